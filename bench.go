@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+func benchCommand() *command {
+	c := &command{
+		name: "bench",
+		args: "file",
+		help: "Measure the parse and format throughput, allocations and peak memory for a document.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		count := fs.Int("count", 1, "number of times every measurement is repeated")
+		benchTime := fs.Duration("benchtime", time.Second, "minimum duration of a measurement")
+
+		return func(args []string) error {
+			if len(args) != 1 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			if *in.repair {
+				return usageErrorf("-repair cannot be used with bench")
+			}
+			if *count < 1 {
+				return usageErrorf("-count must be positive")
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			r, name, err := j.open(args[0])
+			if err != nil {
+				return err
+			}
+			src, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+			doc, err := j.decode(bytes.NewReader(src), name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			layout := jsonparser.IndentOptions{Indent: "  "}
+			benchmarks := []struct {
+				name string
+				fn   func() error
+			}{
+				{"Parse", func() error {
+					_, err := j.decode(bytes.NewReader(src), name)
+					return err
+				}},
+				{"Pretty", func() error { return jsonparser.WritePrettyIndent(io.Discard, doc, layout) }},
+				{"Minify", func() error { return jsonparser.WriteMinified(io.Discard, doc) }},
+			}
+			// the output is understood by benchstat
+			fmt.Printf("file: %s\nsize: %d\n", name, len(src))
+			for _, b := range benchmarks {
+				for range *count {
+					res, err := measure(*benchTime, b.fn)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Benchmark%s/%s\t%s\n", b.name, filepath.Base(name), res.format(len(src)))
+				}
+			}
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			fmt.Printf("peak-memory: %.1f MB\n", float64(stats.Sys)/1e6)
+			return nil
+		}
+	}
+	return c
+}
+
+// benchResult is a measurement of a function run n times.
+type benchResult struct {
+	n       int
+	elapsed time.Duration
+	allocs  uint64
+	bytes   uint64
+}
+
+// measure runs fn as often as needed to take at least d,
+// increasing the number of runs like the testing package.
+func measure(d time.Duration, fn func() error) (benchResult, error) {
+	// warm up, and stop early on errors
+	if err := fn(); err != nil {
+		return benchResult{}, err
+	}
+	for n := 1; ; {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for range n {
+			if err := fn(); err != nil {
+				return benchResult{}, err
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if elapsed >= d || n >= 1e9 {
+			return benchResult{
+				n:       n,
+				elapsed: elapsed,
+				allocs:  after.Mallocs - before.Mallocs,
+				bytes:   after.TotalAlloc - before.TotalAlloc,
+			}, nil
+		}
+		// aim 20% past d, growing at least by one and at most 100 times
+		next := int(1.2 * float64(n) * float64(d) / float64(max(elapsed, time.Microsecond)))
+		n = min(max(next, n+1), 100*n)
+	}
+}
+
+// format returns the measurement in the format of go test -bench,
+// with the throughput for processing size bytes per run.
+func (r benchResult) format(size int) string {
+	n := float64(r.n)
+	perOp := r.elapsed.Seconds() / n
+	return fmt.Sprintf("%8d\t%12.0f ns/op\t%8.2f MB/s\t%10.0f B/op\t%8.0f allocs/op",
+		r.n, perOp*1e9, float64(size)/perOp/1e6, float64(r.bytes)/n, float64(r.allocs)/n)
+}
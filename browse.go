@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+func browseCommand() *command {
+	c := &command{
+		name:    "browse",
+		aliases: []string{"tui"},
+		args:    "[file]",
+		help:    "Browse a document as a collapsible tree in the terminal.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+
+		return func(args []string) error {
+			if len(args) > 1 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			path := "-"
+			if len(args) == 1 {
+				path = args[0]
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			root, err := j.parse(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			// keys are read from the terminal, as the document may be stdin
+			tty, restore, err := openTerminal()
+			if err != nil {
+				return err
+			}
+			b := newBrowser(root, tty)
+			err = b.run(tty, func() (int, int, error) { return terminalSize(tty) })
+			if rerr := restore(); err == nil {
+				err = rerr
+			}
+			return err
+		}
+	}
+	return c
+}
+
+// treeNode is a value shown by the browser. Children are created
+// when the node is first expanded, so that large documents open fast.
+type treeNode struct {
+	el     *jsonparser.Element
+	parent *treeNode
+	// label is the member name or the array index, empty for the document.
+	label string
+	// token is the escaped JSON Pointer token of the node.
+	token string
+	// index is the position of the node in its parent.
+	index    int
+	depth    int
+	expanded bool
+	children []*treeNode
+}
+
+func (n *treeNode) container() bool {
+	return isContainer(n.el)
+}
+
+func (n *treeNode) loadChildren() []*treeNode {
+	if n.children != nil || !n.container() {
+		return n.children
+	}
+	n.children = []*treeNode{}
+	add := func(label, token string, el *jsonparser.Element) {
+		n.children = append(n.children, &treeNode{
+			el:     el,
+			parent: n,
+			label:  label,
+			token:  token,
+			index:  len(n.children),
+			depth:  n.depth + 1,
+		})
+	}
+	if members, ok := n.el.Object(); ok {
+		for _, m := range members {
+			add(m.Key(), strings.NewReplacer("~", "~0", "/", "~1").Replace(m.Key()), m.Value())
+		}
+	} else if elements, ok := n.el.Array(); ok {
+		for i, e := range elements {
+			add(strconv.Itoa(i), strconv.Itoa(i), e)
+		}
+	}
+	return n.children
+}
+
+// pointer returns the JSON Pointer of the node.
+func (n *treeNode) pointer() string {
+	var tokens []string
+	for ; n.parent != nil; n = n.parent {
+		tokens = append(tokens, n.token)
+	}
+	if len(tokens) == 0 {
+		return ""
+	}
+	slices.Reverse(tokens)
+	return "/" + strings.Join(tokens, "/")
+}
+
+// position returns the indices of the node and its ancestors in their
+// parents from the top, which order nodes as they appear in the document.
+func (n *treeNode) position() []int {
+	var pos []int
+	for ; n.parent != nil; n = n.parent {
+		pos = append(pos, n.index)
+	}
+	slices.Reverse(pos)
+	return pos
+}
+
+// browser holds the state of the terminal UI.
+type browser struct {
+	root *treeNode
+	// lines are the visible nodes from the top of the document.
+	lines  []*treeNode
+	cursor int
+	// top is the index of the first line on the screen.
+	top    int
+	width  int
+	height int
+
+	// searching is set while the query is typed.
+	searching bool
+	query     string
+	// matches are the positions of the nodes matching the query.
+	matches [][]int
+	// message is shown in the status line until the next key.
+	message string
+
+	out *bufio.Writer
+}
+
+func newBrowser(root *jsonparser.Element, out io.Writer) *browser {
+	b := &browser{root: &treeNode{el: root, expanded: true}, out: bufio.NewWriter(out)}
+	b.root.loadChildren()
+	b.layout()
+	return b
+}
+
+const browseHelp = "↑↓ move  ←→ collapse/expand  / search  n/N next/previous  y copy path  q quit"
+
+// run shows the browser on the alternate screen and handles the keys
+// read from in until the user quits. size returns the size of the terminal.
+func (b *browser) run(in io.Reader, size func() (int, int, error)) error {
+	fmt.Fprint(b.out, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(b.out, "\x1b[?25h\x1b[?1049l")
+		b.out.Flush()
+	}()
+	b.message = browseHelp
+
+	buf := make([]byte, 64)
+	for {
+		rows, cols, err := size()
+		if err != nil {
+			return err
+		}
+		b.width, b.height = cols, rows
+		if err := b.render(); err != nil {
+			return err
+		}
+		n, err := in.Read(buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !b.key(string(buf[:n])) {
+			return nil
+		}
+	}
+}
+
+// key handles a key, reporting false if the user quits.
+func (b *browser) key(k string) bool {
+	b.message = ""
+	if b.searching {
+		b.searchKey(k)
+		return true
+	}
+	node := b.lines[b.cursor]
+	switch k {
+	case "q", "\x03":
+		return false
+	case "\x1b[A", "k":
+		b.move(-1)
+	case "\x1b[B", "j":
+		b.move(1)
+	case "\x1b[5~", "\x02":
+		b.move(-b.pageSize())
+	case "\x1b[6~", "\x06":
+		b.move(b.pageSize())
+	case "\x1b[H", "g":
+		b.move(-len(b.lines))
+	case "\x1b[F", "G":
+		b.move(len(b.lines))
+	case "\x1b[C", "l":
+		if node.container() && !node.expanded {
+			b.toggle(node)
+		} else if node.expanded && len(node.children) > 0 {
+			b.move(1)
+		}
+	case "\x1b[D", "h":
+		if node.expanded && node.parent != nil {
+			b.toggle(node)
+		} else if node.parent != nil && node.parent.parent != nil {
+			b.cursor = slices.Index(b.lines, node.parent)
+		}
+	case "\r", " ":
+		if node.container() && node.parent != nil {
+			b.toggle(node)
+		}
+	case "/":
+		b.searching, b.query, b.matches = true, "", nil
+	case "n":
+		b.next(1)
+	case "N":
+		b.next(-1)
+	case "y":
+		ptr := node.pointer()
+		// OSC 52 sets the clipboard of terminals supporting it
+		fmt.Fprintf(b.out, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(ptr)))
+		b.message = fmt.Sprintf("copied %q", ptr)
+	}
+	return true
+}
+
+// searchKey handles a key typed into the query,
+// moving to the first match after the cursor as the query changes.
+func (b *browser) searchKey(k string) {
+	switch k {
+	case "\r":
+		b.searching = false
+		if len(b.matches) == 1 {
+			b.message = "1 match"
+		} else {
+			b.message = fmt.Sprintf("%d matches", len(b.matches))
+		}
+		return
+	case "\x1b", "\x03":
+		b.searching, b.query, b.matches = false, "", nil
+		return
+	case "\x7f", "\b":
+		if b.query == "" {
+			return
+		}
+		_, size := utf8.DecodeLastRuneInString(b.query)
+		b.query = b.query[:len(b.query)-size]
+	default:
+		if k[0] < ' ' || !utf8.ValidString(k) {
+			return
+		}
+		b.query += k
+	}
+	b.matches = nil
+	if b.query != "" {
+		b.search(b.root.el, nil, strings.ToLower(b.query))
+	}
+	b.next(0)
+}
+
+// search adds the positions of the values below el, at position pos,
+// whose key or scalar value contains text to the matches.
+func (b *browser) search(el *jsonparser.Element, pos []int, text string) {
+	match := func(i int, key string, value *jsonparser.Element) {
+		p := append(pos[:len(pos):len(pos)], i)
+		if strings.Contains(strings.ToLower(key), text) || !isContainer(value) &&
+			strings.Contains(strings.ToLower(jsonparser.Minify(value)), text) {
+			b.matches = append(b.matches, p)
+		}
+		b.search(value, p, text)
+	}
+	if members, ok := el.Object(); ok {
+		for i, m := range members {
+			match(i, m.Key(), m.Value())
+		}
+	} else if elements, ok := el.Array(); ok {
+		for i, e := range elements {
+			match(i, "", e)
+		}
+	}
+}
+
+// next moves to the match following the cursor for dir 1, the one
+// preceding it for -1, or the first one at or after it for 0.
+func (b *browser) next(dir int) {
+	if len(b.matches) == 0 {
+		if b.query != "" {
+			b.message = fmt.Sprintf("no match for %q", b.query)
+		}
+		return
+	}
+	cur := b.lines[b.cursor].position()
+	i, found := slices.BinarySearchFunc(b.matches, cur, slices.Compare[[]int])
+	switch {
+	case dir > 0 && found:
+		i++
+	case dir < 0:
+		i--
+	}
+	// wrap around
+	i = (i + len(b.matches)) % len(b.matches)
+	b.reveal(b.matches[i])
+	b.message = fmt.Sprintf("match %d of %d", i+1, len(b.matches))
+}
+
+// reveal expands the ancestors of the node at pos and moves to it.
+func (b *browser) reveal(pos []int) {
+	n := b.root
+	for _, i := range pos {
+		if !n.expanded {
+			n.expanded = true
+			n.loadChildren()
+		}
+		n = n.children[i]
+	}
+	b.layout()
+	b.cursor = slices.Index(b.lines, n)
+}
+
+func (b *browser) toggle(n *treeNode) {
+	n.expanded = !n.expanded
+	n.loadChildren()
+	b.layout()
+	b.cursor = slices.Index(b.lines, n)
+}
+
+func (b *browser) move(delta int) {
+	b.cursor = max(0, min(len(b.lines)-1, b.cursor+delta))
+}
+
+// pageSize returns the number of lines showing nodes.
+func (b *browser) pageSize() int {
+	return max(1, b.height-1)
+}
+
+// layout updates the visible lines after nodes are expanded or collapsed.
+func (b *browser) layout() {
+	b.lines = b.lines[:0]
+	var add func(n *treeNode)
+	add = func(n *treeNode) {
+		b.lines = append(b.lines, n)
+		if n.expanded {
+			for _, c := range n.children {
+				add(c)
+			}
+		}
+	}
+	// the document itself is not shown unless it is a scalar
+	if !b.root.container() {
+		add(b.root)
+		return
+	}
+	for _, c := range b.root.children {
+		add(c)
+	}
+	if len(b.lines) == 0 {
+		// an empty object or array
+		b.lines = append(b.lines, b.root)
+	}
+}
+
+// render draws the visible lines and the status line.
+func (b *browser) render() error {
+	page := b.pageSize()
+	if b.cursor < b.top {
+		b.top = b.cursor
+	} else if b.cursor >= b.top+page {
+		b.top = b.cursor - page + 1
+	}
+
+	fmt.Fprint(b.out, "\x1b[H")
+	for i := b.top; i < b.top+page; i++ {
+		if i < len(b.lines) {
+			line := truncate(b.line(b.lines[i]), b.width)
+			if i == b.cursor {
+				line = "\x1b[7m" + line + "\x1b[0m"
+			}
+			fmt.Fprint(b.out, line)
+		}
+		fmt.Fprint(b.out, "\x1b[K\r\n")
+	}
+
+	status := b.message
+	switch {
+	case b.searching:
+		status = "/" + b.query
+	case status == "":
+		status = b.lines[b.cursor].pointer()
+		if status == "" {
+			status = "/"
+		}
+	}
+	fmt.Fprint(b.out, "\x1b[1m", truncate(status, b.width), "\x1b[0m\x1b[K")
+	return b.out.Flush()
+}
+
+// line returns the text of the line showing n.
+func (b *browser) line(n *treeNode) string {
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("  ", max(0, n.depth-1)))
+	switch {
+	case !n.container():
+		sb.WriteString("  ")
+	case n.expanded:
+		sb.WriteString("▾ ")
+	default:
+		sb.WriteString("▸ ")
+	}
+	if n.parent != nil {
+		if n.parent.el.Kind() == jsonparser.ArrayKind {
+			fmt.Fprintf(&sb, "[%s]: ", n.label)
+		} else {
+			fmt.Fprintf(&sb, "%s: ", n.label)
+		}
+	}
+	if n.container() {
+		sb.WriteString(preview(n.el))
+	} else {
+		sb.WriteString(jsonparser.Minify(n.el))
+	}
+	return sb.String()
+}
+
+// truncate shortens s to width characters.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
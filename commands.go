@@ -0,0 +1,588 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	aliases []string
+	// args is the synopsis of the arguments following the flags.
+	args string
+	help string
+	// hidden commands are left out of the usage text.
+	hidden bool
+	// setup registers the flags of the command on fs and returns
+	// the function running it with the arguments left after parsing.
+	setup func(fs *flag.FlagSet) func(args []string) error
+}
+
+// commands returns all commands in the order of the usage text:
+// the core commands followed by the remaining output modes.
+func commands() []*command {
+	cmds := []*command{
+		formatCommand("fmt", "pretty"),
+		formatCommand("min", "minify"),
+		formatCommand("ast", "ast"),
+		validateCommand(),
+		getCommand(),
+		evalCommand(),
+		diffCommand(),
+		patchCommand(),
+		mergeCommand(),
+		benchCommand(),
+		replCommand(),
+		browseCommand(),
+		completionCommand(),
+	}
+	for _, name := range formatterNames() {
+		switch name {
+		case "pretty", "minify", "ast":
+		default:
+			cmds = append(cmds, formatCommand(name, name))
+		}
+	}
+	return cmds
+}
+
+// findCommand returns the command with the given name or alias, or nil.
+func findCommand(name string) *command {
+	for _, c := range commands() {
+		if c.name == name || slices.Contains(c.aliases, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// flagSet returns the flags of the command
+// and the function running it once they are parsed.
+func (c *command) flagSet() (*flag.FlagSet, func(args []string) error) {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	exec := c.setup(fs)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "usage: %s %s [flags] %s\n\n%s\n", programName, c.name, c.args, c.help)
+		if len(c.aliases) > 0 {
+			fmt.Fprintf(w, "\naliases: %s\n", strings.Join(c.aliases, ", "))
+		}
+		fmt.Fprintf(w, "\nflags:\n")
+		fs.PrintDefaults()
+	}
+	return fs, exec
+}
+
+// run runs the command with the flags defaulting to the settings
+// of the project config.
+func (c *command) run(args []string) error {
+	// loaded first as checking it sets up the flags of all commands
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fs, exec := c.flagSet()
+	if cfg != nil {
+		if err := cfg.apply(fs, c.name); err != nil {
+			return err
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return &exitError{code: exitUsage}
+	}
+	return exec(fs.Args())
+}
+
+// outputFlag registers the -o flag.
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("o", "", "write the output to this `file` instead of stdout, replacing it atomically")
+}
+
+// configure resolves the style flags into the settings of j,
+// reformatting as the input is read only if no format option
+// needs the whole document.
+func (f *styleFlags) configure(j *job, allowColor bool) error {
+	opts, layout, err := f.options(allowColor)
+	if err != nil {
+		return err
+	}
+	transform, err := f.transform()
+	if err != nil {
+		return err
+	}
+	j.stream = j.stream && len(opts) == 0 && transform == nil
+	j.finalNewline = *f.finalNewline
+	if j.finalNewline {
+		opts = append(opts, jsonparser.WithFinalNewline())
+	}
+	j.settings.formatOpts = opts
+	j.settings.layout = layout
+	j.settings.transform = transform
+	return nil
+}
+
+// formatCommand returns a command writing its inputs with the formatter
+// named formatterName, which is also an alias of the command.
+func formatCommand(name, formatterName string) *command {
+	formatter := formatters[formatterName]
+	c := &command{name: name, args: "[file ...]", help: formatter.Help()}
+	if name != formatterName {
+		c.aliases = []string{formatterName}
+	}
+	reformat := formatterName == "pretty" || formatterName == "minify"
+
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		filter := addFileFlags(fs)
+		outPath := outputFlag(fs)
+		query := fs.String("q", "", "write only the value at this JSON Pointer or path expression such as .store.book[0].title, or the array of the values matching this JSONPath expression such as $..book[?@.price < 10]")
+		predicate := fs.String("filter", "", "write only the elements of the array, selected by -q if given, for which this jq-like `expression` such as '.age > 30' is true")
+		sortBy := fs.String("sort-by", "", "sort the array, selected by -q and -filter if given, by the values of this jq-like `expression` such as .name or '.last, .first'")
+		sortOrder := fs.String("sort-order", "asc", "order of -sort-by, one of asc|desc")
+		var pick, omit []string
+		fs.Func("pick", "write only the members at this dotted `path` such as name, address.city or **.id, and the objects leading to them (repeatable)", func(s string) error {
+			pick = append(pick, s)
+			return nil
+		})
+		fs.Func("omit", "write the document without the members at this dotted `path` such as **.password (repeatable)", func(s string) error {
+			omit = append(omit, s)
+			return nil
+		})
+		var (
+			s                                       settings
+			style                                   *styleFlags
+			write, diff, list, check, lines, stream *bool
+		)
+		if reformat {
+			style = addStyleFlags(fs, formatterName == "pretty")
+			write = fs.Bool("w", false, "write the result to the input files instead of stdout")
+			diff = fs.Bool("d", false, "print a unified diff of the changes formatting would make")
+			list = fs.Bool("l", false, "print the names of the files whose formatting differs")
+			check = fs.Bool("check", false, "like -l, but exit with 1 if the formatting of a file differs")
+			lines = fs.Bool("lines", false, "read newline-delimited JSON and write every record as soon as it is read")
+			stream = fs.Bool("stream", false, "reformat without holding the document in memory, keeping the output written before a syntax error; ignored with -q, -lines and options other than the layout")
+		}
+		if f, ok := formatter.(flagger); ok {
+			f.Flags(fs, &s)
+		}
+
+		return func(args []string) error {
+			j, err := in.job(formatter)
+			if err != nil {
+				return err
+			}
+			j.settings = s
+			if *query != "" {
+				if j.query, err = parseQuery(*query); err != nil {
+					return err
+				}
+			}
+			if *predicate != "" {
+				sel, err := filterSelector(*predicate)
+				if err != nil {
+					return err
+				}
+				j.query = chain(j.query, sel)
+			}
+			if *sortBy != "" {
+				sel, err := sortSelector(*sortBy, *sortOrder)
+				if err != nil {
+					return err
+				}
+				j.query = chain(j.query, sel)
+			}
+			if len(pick) > 0 || len(omit) > 0 {
+				j.query = chain(j.query, projectSelector(pick, omit))
+			}
+			rewrite := reformat && (*write || *diff || *list || *check)
+			if rewrite && j.query != nil {
+				return usageErrorf("-q, -filter, -sort-by, -pick and -omit cannot be combined with -w, -d, -l and -check")
+			}
+			if reformat {
+				// comments in the input are only kept by parsing it,
+				// and queries need the whole document
+				j.stream = *stream && in.plain() && !*lines && j.query == nil
+				if err := style.configure(j, !rewrite); err != nil {
+					return err
+				}
+				if *lines {
+					if *in.ndjson || *in.repair {
+						return usageErrorf("-lines cannot be combined with -ndjson or -repair")
+					}
+					j.lines = true
+					// records are separated by line endings
+					if !j.finalNewline {
+						j.settings.formatOpts = append(j.settings.formatOpts, jsonparser.WithFinalNewline())
+					}
+				}
+			}
+			if rewrite && *outPath != "" {
+				return usageErrorf("-w, -d, -l and -check cannot be combined with -o")
+			}
+			if rewrite && *check && *write {
+				return usageErrorf("-check cannot be combined with -w")
+			}
+
+			inputs, err := expandInputs(args, filter)
+			if err != nil {
+				return err
+			}
+			if rewrite {
+				opts := rewriteOptions{write: *write, diff: *diff, list: *list || *check}
+				changed, err := j.rewriteAll(os.Stdout, inputs, opts)
+				if !*check {
+					return err
+				}
+				if err != nil {
+					return err
+				}
+				switch changed {
+				case 0:
+					return nil
+				case 1:
+					return &exitError{code: exitInvalid, err: errors.New("1 file is not formatted")}
+				default:
+					return &exitError{code: exitInvalid, err: fmt.Errorf("%d files are not formatted", changed)}
+				}
+			}
+
+			w, finish, err := openOutput(*outPath)
+			if err != nil {
+				return err
+			}
+			return finish(j.processAll(w, inputs))
+		}
+	}
+	return c
+}
+
+func validateCommand() *command {
+	c := &command{
+		name: "validate",
+		args: "[file ...]",
+		help: "Check that documents are valid, exiting with 1 if one is not.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		filter := addFileFlags(fs)
+		errorFormat := fs.String("error-format", "text", "how errors are reported, one of text|json")
+
+		return func(args []string) error {
+			var jsonErrors bool
+			switch *errorFormat {
+			case "text":
+			case "json":
+				jsonErrors = true
+			default:
+				return usageErrorf("unsupported error format: %q", *errorFormat)
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			if !*in.repair {
+				// report every error rather than the first one
+				j.opts = append(j.opts, jsonparser.WithRecovery())
+			}
+
+			inputs, err := expandInputs(args, filter)
+			if err != nil {
+				return err
+			}
+			return j.validate(os.Stdout, inputs, jsonErrors)
+		}
+	}
+	return c
+}
+
+func getCommand() *command {
+	c := &command{
+		name: "get",
+		args: "query [file ...]",
+		help: "Print the value at a JSON Pointer or path expression such as .store.book[0].title, or the values matching a JSONPath expression.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		filter := addFileFlags(fs)
+		style := addStyleFlags(fs, true)
+		outPath := outputFlag(fs)
+
+		return func(args []string) error {
+			if len(args) == 0 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			j, err := in.job(formatters["pretty"])
+			if err != nil {
+				return err
+			}
+			if j.query, err = parseQuery(args[0]); err != nil {
+				return err
+			}
+			if err := style.configure(j, true); err != nil {
+				return err
+			}
+
+			inputs, err := expandInputs(args[1:], filter)
+			if err != nil {
+				return err
+			}
+			w, finish, err := openOutput(*outPath)
+			if err != nil {
+				return err
+			}
+			return finish(j.processAll(w, inputs))
+		}
+	}
+	return c
+}
+
+func evalCommand() *command {
+	c := &command{
+		name: "eval",
+		args: "filter [file ...]",
+		help: "Print the outputs of a jq-like filter such as '.items[] | select(.active) | {id, name}' on documents.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		filter := addFileFlags(fs)
+		style := addStyleFlags(fs, true)
+		outPath := outputFlag(fs)
+		raw := fs.Bool("r", false, "write strings without quotes and escapes")
+
+		return func(args []string) error {
+			if len(args) == 0 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			f, err := jsonparser.CompileFilter(args[0])
+			if err != nil {
+				return &exitError{code: exitUsage, err: err}
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			eol := "\n"
+			if *style.eol == "crlf" {
+				eol = "\r\n"
+			}
+			pretty := formatters["pretty"]
+			j.formatter = formatterFunc{
+				name: "eval",
+				format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+					outputs, err := f.Eval(json)
+					for _, out := range outputs {
+						if str, ok := out.Str(); ok && *raw {
+							if _, err := io.WriteString(w, str+eol); err != nil {
+								return err
+							}
+						} else if err := pretty.Format(w, out, s); err != nil {
+							return err
+						}
+					}
+					return err
+				},
+			}
+			if err := style.configure(j, true); err != nil {
+				return err
+			}
+			// outputs are separated by line endings
+			if !j.finalNewline {
+				j.settings.formatOpts = append(j.settings.formatOpts, jsonparser.WithFinalNewline())
+			}
+
+			inputs, err := expandInputs(args[1:], filter)
+			if err != nil {
+				return err
+			}
+			w, finish, err := openOutput(*outPath)
+			if err != nil {
+				return err
+			}
+			return finish(j.processAll(w, inputs))
+		}
+	}
+	return c
+}
+
+func diffCommand() *command {
+	c := &command{
+		name: "diff",
+		args: "old new",
+		help: "Print the values added, removed and modified between two documents regardless of formatting and member order, exiting with 1 if they differ.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		color := fs.String("color", "auto", "colorize the output, one of auto|always|never")
+
+		return func(args []string) error {
+			if len(args) != 2 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			colorize, err := useColor(*color)
+			if err != nil {
+				return err
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+
+			var docs [2]*jsonparser.Element
+			for i, path := range args {
+				if docs[i], err = j.parse(path); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+			changes := jsonparser.Diff(docs[0], docs[1])
+			if len(changes) == 0 {
+				return nil
+			}
+			if err := writeChanges(os.Stdout, changes, colorize); err != nil {
+				return err
+			}
+			return &exitError{code: exitInvalid}
+		}
+	}
+	return c
+}
+
+func patchCommand() *command {
+	c := &command{
+		name: "patch",
+		args: "document patch",
+		help: "Apply a JSON Patch (RFC 6902) to a document and print the result.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		style := addStyleFlags(fs, true)
+		outPath := outputFlag(fs)
+
+		return func(args []string) error {
+			if len(args) != 2 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			patch, err := j.parse(args[1])
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[1], err)
+			}
+
+			pretty := formatters["pretty"]
+			j.formatter = formatterFunc{
+				name: "patch",
+				format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+					if err := jsonparser.ApplyPatch(json, patch); err != nil {
+						return err
+					}
+					return pretty.Format(w, json, s)
+				},
+			}
+			if err := style.configure(j, true); err != nil {
+				return err
+			}
+
+			w, finish, err := openOutput(*outPath)
+			if err != nil {
+				return err
+			}
+			return finish(j.process(w, args[0]))
+		}
+	}
+	return c
+}
+
+func mergeCommand() *command {
+	c := &command{
+		name: "merge",
+		args: "base overlay ...",
+		help: "Merge overlays into a document in order and print the result, as JSON Merge Patches (RFC 7386) by default.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		style := addStyleFlags(fs, true)
+		outPath := outputFlag(fs)
+		deep := fs.Bool("deep", false, "merge objects recursively as -arrays and -nulls say instead of applying JSON Merge Patches")
+		arrays := fs.String("arrays", "replace", "how -deep merges arrays, one of replace|append|index")
+		nulls := fs.String("nulls", "keep", "how -deep merges null members, one of keep|delete")
+
+		return func(args []string) error {
+			if len(args) < 2 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			var opts jsonparser.MergeOptions
+			switch *arrays {
+			case "replace":
+			case "append":
+				opts.Arrays = jsonparser.AppendArrays
+			case "index":
+				opts.Arrays = jsonparser.MergeArraysByIndex
+			default:
+				return usageErrorf("unsupported array merge: %q", *arrays)
+			}
+			switch *nulls {
+			case "keep":
+			case "delete":
+				opts.Nulls = jsonparser.DeleteNulls
+			default:
+				return usageErrorf("unsupported null merge: %q", *nulls)
+			}
+			if !*deep && (*arrays != "replace" || *nulls != "keep") {
+				return usageErrorf("-arrays and -nulls require -deep")
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			overlays := make([]*jsonparser.Element, len(args)-1)
+			for i, path := range args[1:] {
+				if overlays[i], err = j.parse(path); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+
+			pretty := formatters["pretty"]
+			j.formatter = formatterFunc{
+				name: "merge",
+				format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+					for _, overlay := range overlays {
+						if *deep {
+							json = jsonparser.Merge(json, overlay, opts)
+						} else {
+							jsonparser.ApplyMergePatch(json, overlay)
+						}
+					}
+					return pretty.Format(w, json, s)
+				},
+			}
+			if err := style.configure(j, true); err != nil {
+				return err
+			}
+
+			w, finish, err := openOutput(*outPath)
+			if err != nil {
+				return err
+			}
+			return finish(j.process(w, args[0]))
+		}
+	}
+	return c
+}
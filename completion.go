@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+func completionCommand() *command {
+	c := &command{
+		name:   "completion",
+		args:   "bash|zsh|fish",
+		help:   "Print a shell completion script.",
+		hidden: true,
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		return func(args []string) error {
+			if len(args) != 1 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			var buf bytes.Buffer
+			switch args[0] {
+			case "bash":
+				bashCompletion(&buf)
+			case "zsh":
+				zshCompletion(&buf)
+			case "fish":
+				fishCompletion(&buf)
+			default:
+				return usageErrorf("unsupported shell: %q", args[0])
+			}
+			_, err := buf.WriteTo(os.Stdout)
+			return err
+		}
+	}
+	return c
+}
+
+// completionFlag describes a flag of a command for completion scripts.
+type completionFlag struct {
+	name  string
+	usage string
+	// arg is the name of the value of the flag, empty for boolean flags.
+	arg        string
+	repeatable bool
+	// values are the allowed values listed as "one of a|b" by the usage.
+	values []string
+}
+
+// helpHelp describes the help command, which is not a command of its own.
+const helpHelp = "Show the usage of the program or of a command."
+
+var enumUsage = regexp.MustCompile(`one of ([\w-]+(?:\|[\w-]+)+)`)
+
+// completionCommands returns the commands shown by completions
+// with their flags.
+func completionCommands() ([]*command, [][]completionFlag) {
+	var (
+		cmds  []*command
+		flags [][]completionFlag
+	)
+	for _, c := range commands() {
+		if c.hidden {
+			continue
+		}
+		fs, _ := c.flagSet()
+		var cf []completionFlag
+		fs.VisitAll(func(f *flag.Flag) {
+			arg, usage := flag.UnquoteUsage(f)
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+				arg = ""
+			}
+			cf = append(cf, completionFlag{
+				name:       f.Name,
+				usage:      usage,
+				arg:        arg,
+				repeatable: strings.HasSuffix(usage, "(repeatable)"),
+			})
+			if m := enumUsage.FindStringSubmatch(usage); m != nil {
+				cf[len(cf)-1].values = strings.Split(m[1], "|")
+			}
+		})
+		cmds = append(cmds, c)
+		flags = append(flags, cf)
+	}
+	return cmds, flags
+}
+
+// commandNames returns the name and the aliases of c.
+func commandNames(c *command) []string {
+	return append([]string{c.name}, c.aliases...)
+}
+
+// shellIdent returns s with the characters not allowed in
+// shell function names replaced.
+func shellIdent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// shellQuote quotes s for bash and zsh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func bashCompletion(w io.Writer) {
+	cmds, flags := completionCommands()
+	names := []string{"help"}
+	for _, c := range cmds {
+		names = append(names, commandNames(c)...)
+	}
+
+	fn := "_" + shellIdent(programName)
+	fmt.Fprintf(w, "# bash completion for %s, generated by '%[1]s completion bash'\n\n", programName)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} flags\n")
+	fmt.Fprintf(w, "\tif ((COMP_CWORD == 1)) || [[ ${COMP_WORDS[1]} == help && COMP_CWORD -eq 2 ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(names, " ")))
+	fmt.Fprintf(w, "\t\treturn\n\tfi\n\n")
+	fmt.Fprintf(w, "\tcase ${COMP_WORDS[1]} in\n")
+	for i, c := range cmds {
+		fmt.Fprintf(w, "\t%s)\n", strings.Join(commandNames(c), "|"))
+		var all, valued []string
+		fmt.Fprintf(w, "\t\tcase $prev in\n")
+		for _, f := range flags[i] {
+			all = append(all, "-"+f.name)
+			switch {
+			case f.values != nil:
+				fmt.Fprintf(w, "\t\t-%s) COMPREPLY=($(compgen -W %s -- \"$cur\")); return ;;\n",
+					f.name, shellQuote(strings.Join(f.values, " ")))
+			case f.arg != "":
+				valued = append(valued, "-"+f.name)
+			}
+		}
+		if valued != nil {
+			// completed by the default completion of file names
+			fmt.Fprintf(w, "\t\t%s) return ;;\n", strings.Join(valued, "|"))
+		}
+		fmt.Fprintf(w, "\t\tesac\n")
+		fmt.Fprintf(w, "\t\tflags=%s\n\t\t;;\n", shellQuote(strings.Join(all, " ")))
+	}
+	fmt.Fprintf(w, "\tesac\n\n")
+	fmt.Fprintf(w, "\tif [[ $cur == -* ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "\tfi\n}\n\n")
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, programName)
+}
+
+func zshCompletion(w io.Writer) {
+	cmds, flags := completionCommands()
+	// describe escapes the special characters of option descriptions
+	describe := strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace
+
+	fn := "_" + shellIdent(programName)
+	fmt.Fprintf(w, "#compdef %s\n", programName)
+	fmt.Fprintf(w, "# zsh completion for %s, generated by '%[1]s completion zsh'\n\n", programName)
+	fmt.Fprintf(w, "compdef %s %s\n\n", fn, programName)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "\tlocal -a commands=(\n")
+	fmt.Fprintf(w, "\t\t%s\n", shellQuote("help:"+helpHelp))
+	for _, c := range cmds {
+		for _, name := range commandNames(c) {
+			fmt.Fprintf(w, "\t\t%s\n", shellQuote(name+":"+c.help))
+		}
+	}
+	fmt.Fprintf(w, "\t)\n")
+	fmt.Fprintf(w, "\tif ((CURRENT == 2)) || [[ $words[2] == help && CURRENT -eq 3 ]]; then\n")
+	fmt.Fprintf(w, "\t\t_describe -t commands command commands\n")
+	fmt.Fprintf(w, "\t\treturn\n\tfi\n\n")
+	fmt.Fprintf(w, "\tlocal cmd=$words[2]\n\tshift words\n\t((CURRENT--))\n")
+	fmt.Fprintf(w, "\tcase $cmd in\n")
+	for i, c := range cmds {
+		fmt.Fprintf(w, "\t%s)\n\t\t_arguments \\\n", strings.Join(commandNames(c), "|"))
+		for _, f := range flags[i] {
+			spec := "-" + f.name + "[" + describe(f.usage) + "]"
+			if f.repeatable {
+				spec = "*" + spec
+			}
+			message := strings.ReplaceAll(f.arg, ":", "")
+			switch {
+			case f.values != nil:
+				spec += ":" + message + ":(" + strings.Join(f.values, " ") + ")"
+			case f.arg == "file":
+				spec += ":file:_files"
+			case f.arg != "":
+				spec += ":" + message + ":"
+			}
+			fmt.Fprintf(w, "\t\t\t%s \\\n", shellQuote(spec))
+		}
+		fmt.Fprintf(w, "\t\t\t'*:file:_files'\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, "\tesac\n}\n\n")
+	// the function is only run when autoloaded, not when sourced
+	fmt.Fprintf(w, "if [[ $funcstack[1] == %s ]]; then\n\t%[1]s \"$@\"\nfi\n", fn)
+}
+
+func fishCompletion(w io.Writer) {
+	cmds, flags := completionCommands()
+	quote := func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	names := []string{"help"}
+	for _, c := range cmds {
+		names = append(names, commandNames(c)...)
+	}
+
+	fmt.Fprintf(w, "# fish completion for %s, generated by '%[1]s completion fish'\n\n", programName)
+	fmt.Fprintf(w, "complete -c %s -f\n", programName)
+	fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a help -d %s\n", programName, quote(helpHelp))
+	for _, c := range cmds {
+		for _, name := range commandNames(c) {
+			fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", programName, name, quote(c.help))
+		}
+	}
+	fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from help' -a %s\n",
+		programName, quote(strings.Join(names, " ")))
+	for i, c := range cmds {
+		cond := quote("__fish_seen_subcommand_from " + strings.Join(commandNames(c), " "))
+		fmt.Fprintf(w, "\ncomplete -c %s -n %s -F\n", programName, cond)
+		for _, f := range flags[i] {
+			fmt.Fprintf(w, "complete -c %s -n %s -o %s -d %s", programName, cond, f.name, quote(f.usage))
+			switch {
+			case f.values != nil:
+				fmt.Fprintf(w, " -x -a %s", quote(strings.Join(f.values, " ")))
+			case f.arg == "file":
+				fmt.Fprintf(w, " -r -F")
+			case f.arg != "":
+				fmt.Fprintf(w, " -x")
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// configNames are the names of project config files, looked up in the
+// working directory and then in its parents.
+var configNames = []string{".jsonparser.yaml", ".jsonparser.yml", "jsonparser.toml", ".jsonparser.toml"}
+
+// projectConfig holds defaults for flags read from a project config file.
+// Top-level settings apply to every command defining the flag, and those
+// in a section named after a command to that command only, for example
+//
+//	indent: 4
+//	exclude: [vendor/, "*.min.json"]
+//	min:
+//	  sort-keys: true
+//
+// or in TOML
+//
+//	indent = 4
+//	exclude = ["vendor/", "*.min.json"]
+//	[min]
+//	sort-keys = true
+type projectConfig struct {
+	path    string
+	entries []configEntry
+}
+
+// configEntry sets the flag key to values in order.
+type configEntry struct {
+	// section is the name of the command the entry applies to,
+	// or empty if it applies to all commands.
+	section string
+	key     string
+	values  []string
+	line    int
+}
+
+// loadConfig returns the project config found for the working directory,
+// or nil if there is none.
+func loadConfig() (*projectConfig, error) {
+	path, err := findConfig()
+	if err != nil || path == "" {
+		return nil, err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &projectConfig{path: path}
+	if filepath.Ext(path) == ".toml" {
+		err = c.parseTOML(string(src))
+	} else {
+		err = c.parseYAML(string(src))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, c.check()
+}
+
+// findConfig returns the path of the config file in the working directory
+// or its closest parent having one, or an empty path if there is none.
+func findConfig() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, name := range configNames {
+			path := filepath.Join(dir, name)
+			_, err := os.Stat(path)
+			if err == nil {
+				return path, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func (c *projectConfig) errorf(line int, format string, a ...any) error {
+	return usageErrorf("%s:%d: %s", c.path, line, fmt.Sprintf(format, a...))
+}
+
+// parseTOML reads the subset of TOML made of key/value pairs and tables
+// holding them, with values on a single line.
+func (c *projectConfig) parseTOML(src string) error {
+	var section string
+	for i, line := range strings.Split(src, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(stripConfigComment(line))
+		switch {
+		case line == "":
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return c.errorf(n, "invalid table header")
+			}
+			name, err := parseConfigScalar(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return c.errorf(n, "%v", err)
+			}
+			section = name
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return c.errorf(n, "want key = value")
+			}
+			if err := c.add(n, section, strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseYAML reads the subset of YAML made of mappings of scalars and
+// of lists of scalars, nested in at most one mapping named after a command.
+func (c *projectConfig) parseYAML(src string) error {
+	var (
+		section string
+		// pending is the top-level key without a value on its line,
+		// starting either a section or a list
+		pending     string
+		pendingLine int
+		// list is the index of the entry taking list items, or -1
+		list = -1
+	)
+	closeList := func() error {
+		if list >= 0 && len(c.entries[list].values) == 0 {
+			return c.errorf(c.entries[list].line, "missing value for %q", c.entries[list].key)
+		}
+		list = -1
+		return nil
+	}
+
+	for i, line := range strings.Split(src, "\n") {
+		n := i + 1
+		line = strings.TrimRight(stripConfigComment(line), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content == "---" {
+			continue
+		}
+		if content[0] == '\t' {
+			return c.errorf(n, "tabs cannot be used for indentation")
+		}
+		indented := len(content) < len(line)
+
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			if pending != "" {
+				c.entries = append(c.entries, configEntry{key: pending, line: pendingLine})
+				list, pending = len(c.entries)-1, ""
+			}
+			if list < 0 {
+				return c.errorf(n, "unexpected list item")
+			}
+			value, err := parseConfigScalar(strings.TrimSpace(content[1:]))
+			if err != nil {
+				return c.errorf(n, "%v", err)
+			}
+			c.entries[list].values = append(c.entries[list].values, value)
+			continue
+		}
+
+		if err := closeList(); err != nil {
+			return err
+		}
+		key, value, ok := strings.Cut(content, ":")
+		if !ok || value != "" && value[0] != ' ' {
+			return c.errorf(n, "want key: value")
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !indented {
+			if pending != "" {
+				return c.errorf(pendingLine, "missing value for %q", pending)
+			}
+			section = ""
+			if value == "" {
+				pending, pendingLine = key, n
+				continue
+			}
+		} else {
+			if pending != "" {
+				section, pending = pending, ""
+			}
+			if section == "" {
+				return c.errorf(n, "unexpected indentation")
+			}
+			if value == "" {
+				c.entries = append(c.entries, configEntry{section: section, key: key, line: n})
+				list = len(c.entries) - 1
+				continue
+			}
+		}
+		if err := c.add(n, section, key, value); err != nil {
+			return err
+		}
+	}
+	if pending != "" {
+		return c.errorf(pendingLine, "missing value for %q", pending)
+	}
+	return closeList()
+}
+
+// add adds the entry for key set to value, a scalar or a list of scalars
+// in brackets.
+func (c *projectConfig) add(line int, section, key, value string) error {
+	key, err := parseConfigScalar(key)
+	if err != nil {
+		return c.errorf(line, "%v", err)
+	}
+	e := configEntry{section: section, key: key, line: line}
+	if strings.HasPrefix(value, "[") {
+		if !strings.HasSuffix(value, "]") {
+			return c.errorf(line, "missing ']'")
+		}
+		e.values = []string{}
+		items := splitConfigList(value[1 : len(value)-1])
+		for i, item := range items {
+			item = strings.TrimSpace(item)
+			if item == "" && i == len(items)-1 {
+				// trailing comma
+				break
+			}
+			v, err := parseConfigScalar(item)
+			if err != nil {
+				return c.errorf(line, "%v", err)
+			}
+			e.values = append(e.values, v)
+		}
+	} else {
+		v, err := parseConfigScalar(value)
+		if err != nil {
+			return c.errorf(line, "%v", err)
+		}
+		e.values = []string{v}
+	}
+	c.entries = append(c.entries, e)
+	return nil
+}
+
+// check reports settings which are not flags of any command
+// and sections which are not commands, and resolves command aliases.
+func (c *projectConfig) check() error {
+	var (
+		cmds  = commands()
+		flags = make(map[string]*flag.FlagSet, len(cmds))
+	)
+	for _, cmd := range cmds {
+		flags[cmd.name], _ = cmd.flagSet()
+	}
+	for i, e := range c.entries {
+		if e.section != "" {
+			cmd := findCommand(e.section)
+			if cmd == nil {
+				return c.errorf(e.line, "unknown command %q", e.section)
+			}
+			if flags[cmd.name].Lookup(e.key) == nil {
+				return c.errorf(e.line, "%s has no flag -%s", cmd.name, e.key)
+			}
+			c.entries[i].section = cmd.name
+			continue
+		}
+		var defined bool
+		for _, fs := range flags {
+			defined = defined || fs.Lookup(e.key) != nil
+		}
+		if !defined {
+			return c.errorf(e.line, "unknown flag -%s", e.key)
+		}
+	}
+	return nil
+}
+
+// apply sets the flags of the command named name defined in fs
+// to the values of the config, the ones of its section last.
+func (c *projectConfig) apply(fs *flag.FlagSet, name string) error {
+	for _, section := range []string{"", name} {
+		for _, e := range c.entries {
+			if e.section != section || fs.Lookup(e.key) == nil {
+				continue
+			}
+			for _, v := range e.values {
+				if err := fs.Set(e.key, v); err != nil {
+					return c.errorf(e.line, "invalid value %q for -%s: %v", v, e.key, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// stripConfigComment removes the comment starting with a '#'
+// outside of quotes at the start of line or after a space.
+func stripConfigComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote == '"' && ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitConfigList splits s at the commas outside of quotes.
+func splitConfigList(s string) []string {
+	var (
+		items []string
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote == '"' && ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// parseConfigScalar returns the value of the plain, double-quoted
+// or single-quoted scalar s.
+func parseConfigScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted string %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid quoted string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
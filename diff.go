@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// writeChanges writes a line for every change: the path of the value
+// prefixed with '+' if it was added, '-' if it was removed and '~' if it
+// was modified, followed by the old and the new value. The lines are
+// colored if colorize is set.
+func writeChanges(w io.Writer, changes []jsonparser.Change, colorize bool) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range changes {
+		path := c.Path
+		if path == "" {
+			path = "(document)"
+		}
+		var line, color string
+		switch c.Kind {
+		case jsonparser.ChangeAdded:
+			line, color = fmt.Sprintf("+ %s: %s", path, jsonparser.Minify(c.New)), "32"
+		case jsonparser.ChangeRemoved:
+			line, color = fmt.Sprintf("- %s: %s", path, jsonparser.Minify(c.Old)), "31"
+		default:
+			line, color = fmt.Sprintf("~ %s: %s -> %s", path, jsonparser.Minify(c.Old), jsonparser.Minify(c.New)), "33"
+		}
+		if colorize {
+			line = "\x1b[" + color + "m" + line + "\x1b[0m"
+		}
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// diffContext is the number of unchanged lines around changes in hunks.
+const diffContext = 3
+
+// maxDiffCells bounds the size of the table computing the longest common
+// subsequence of lines; larger differences are shown as replacing all lines.
+const maxDiffCells = 1 << 22
+
+type lineEdit struct {
+	// op is ' ' for a kept line, '-' for a removed and '+' for an added one.
+	op   byte
+	line []byte
+}
+
+// unifiedDiff returns the changes from a to b in unified format,
+// or nil if they are equal.
+func unifiedDiff(oldName, newName string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	// the number of lines of a and b before every edit
+	aPos := make([]int, len(edits)+1)
+	bPos := make([]int, len(edits)+1)
+	for i, e := range edits {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if e.op != '+' {
+			aPos[i+1]++
+		}
+		if e.op != '-' {
+			bPos[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+
+		start := max(i-diffContext, 0)
+		end := i
+		for k := i; k < len(edits) && k-end <= 2*diffContext; k++ {
+			if edits[k].op != ' ' {
+				end = k
+			}
+		}
+		stop := min(end+diffContext+1, len(edits))
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(aPos[start], aPos[stop]-aPos[start]),
+			hunkRange(bPos[start], bPos[stop]-bPos[start]))
+		for _, e := range edits[start:stop] {
+			out.WriteByte(e.op)
+			out.Write(e.line)
+			if !bytes.HasSuffix(e.line, []byte("\n")) {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return out.Bytes()
+}
+
+func hunkRange(pos, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	if n == 1 {
+		return fmt.Sprint(pos + 1)
+	}
+	return fmt.Sprintf("%d,%d", pos+1, n)
+}
+
+// splitLines splits b after every line feed.
+func splitLines(b []byte) [][]byte {
+	lines := bytes.SplitAfter(b, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns an edit script turning a into b
+// based on their longest common subsequence.
+func diffLines(a, b [][]byte) []lineEdit {
+	var prefix, suffix []lineEdit
+	for len(a) > 0 && len(b) > 0 && bytes.Equal(a[0], b[0]) {
+		prefix = append(prefix, lineEdit{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && bytes.Equal(a[len(a)-1], b[len(b)-1]) {
+		suffix = append([]lineEdit{{' ', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	edits := prefix
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			edits = append(edits, lineEdit{'-', line})
+		}
+		for _, line := range b {
+			edits = append(edits, lineEdit{'+', line})
+		}
+		return append(edits, suffix...)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if bytes.Equal(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && bytes.Equal(a[i], b[j]):
+			edits = append(edits, lineEdit{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, lineEdit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, lineEdit{'+', b[j]})
+			j++
+		}
+	}
+	return append(edits, suffix...)
+}
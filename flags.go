@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// inputFlags are the flags controlling how inputs are read and parsed.
+type inputFlags struct {
+	maxSize *int64
+	timeout *time.Duration
+	header  http.Header
+	jsonc   *bool
+	dialect *string
+	ndjson  *bool
+	profile *string
+	repair  *bool
+	strict  *bool
+	workers *int
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	f := &inputFlags{
+		maxSize: fs.Int64("max-size", 1<<30, "maximum input size in bytes, 0 disables the limit"),
+		timeout: fs.Duration("timeout", 30*time.Second, "time limit for downloading URL inputs"),
+		header:  make(http.Header),
+		jsonc:   fs.Bool("jsonc", false, "allow // and /* */ comments"),
+		dialect: fs.String("dialect", "json", "input syntax, one of json|hjson"),
+		ndjson:  fs.Bool("ndjson", false, "read newline-delimited JSON records into an array"),
+		profile: fs.String("profile", "", "option preset, one of strict|lenient|json5"),
+		repair:  fs.Bool("repair", false, "fix common breakage such as missing brackets and commas, reporting every fix"),
+		strict:  fs.Bool("strict", false, "reject duplicate keys, invalid UTF-8 and numbers float64 cannot represent exactly"),
+		workers: fs.Int("j", runtime.GOMAXPROCS(0), "number of inputs processed in parallel"),
+	}
+	fs.Func("header", "add the HTTP header given as `name: value` to requests for URL inputs (repeatable)", func(s string) error {
+		name, value, ok := strings.Cut(s, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return errors.New("want name: value")
+		}
+		f.header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		return nil
+	})
+	return f
+}
+
+// options returns the parser options selected by the flags.
+func (f *inputFlags) options() ([]jsonparser.Option, error) {
+	opts := []jsonparser.Option{jsonparser.WithMaxInputSize(*f.maxSize)}
+	switch *f.profile {
+	case "":
+	case "strict":
+		opts = append(opts, jsonparser.WithProfile(jsonparser.ProfileStrict))
+	case "lenient":
+		opts = append(opts, jsonparser.WithProfile(jsonparser.ProfileLenient))
+	case "json5":
+		opts = append(opts, jsonparser.WithProfile(jsonparser.ProfileJSON5))
+	default:
+		return nil, usageErrorf("unsupported profile: %q", *f.profile)
+	}
+	if *f.jsonc {
+		opts = append(opts, jsonparser.WithComments())
+	}
+	switch *f.dialect {
+	case "json":
+	case "hjson":
+		opts = append(opts, jsonparser.WithDialect(jsonparser.DialectHJSON))
+	default:
+		return nil, usageErrorf("unsupported dialect: %q", *f.dialect)
+	}
+	if *f.strict {
+		if *f.repair {
+			return nil, usageErrorf("-strict cannot be combined with -repair")
+		}
+		// after the profile to override it
+		opts = append(opts,
+			jsonparser.WithDuplicateKeyPolicy(jsonparser.DuplicateKeysReject),
+			jsonparser.WithInvalidUTF8(jsonparser.UTF8Reject),
+			jsonparser.WithExactNumbers())
+	}
+	return opts, nil
+}
+
+// plain reports whether inputs are parsed as standard JSON,
+// so that they can be reformatted as they are read.
+func (f *inputFlags) plain() bool {
+	return !*f.repair && !*f.ndjson && !*f.jsonc && !*f.strict && *f.profile == "" && *f.dialect == "json"
+}
+
+// job returns a job parsing inputs as selected by the flags
+// and writing them with formatter.
+func (f *inputFlags) job(formatter Formatter) (*job, error) {
+	opts, err := f.options()
+	if err != nil {
+		return nil, err
+	}
+	return &job{
+		formatter: formatter,
+		opts:      opts,
+		maxSize:   *f.maxSize,
+		client:    &http.Client{Timeout: *f.timeout},
+		header:    f.header,
+		repair:    *f.repair,
+		ndjson:    *f.ndjson,
+		workers:   *f.workers,
+	}, nil
+}
+
+// styleFlags are the flags controlling how JSON is written.
+type styleFlags struct {
+	pretty bool
+
+	sortKeys         *bool
+	normalizeEscapes *bool
+	output           *string
+	numbers          *string
+	decimals         *int
+	eol              *string
+	finalNewline     *bool
+	keyCase          *string
+	renames          map[string]string
+	redact           []string
+	redactHash       *bool
+
+	// set for pretty output only
+	indent        *int
+	useTabs       *bool
+	compactArrays *bool
+	maxWidth      *int
+	color         *string
+}
+
+func addStyleFlags(fs *flag.FlagSet, pretty bool) *styleFlags {
+	f := &styleFlags{
+		pretty:           pretty,
+		sortKeys:         fs.Bool("sort-keys", false, "emit object members ordered by key"),
+		normalizeEscapes: fs.Bool("normalize-escapes", false, "re-encode strings with the shortest escaping"),
+		output:           fs.String("output", "json", "output syntax, one of json|jsonc|json5"),
+		numbers:          fs.String("numbers", "preserve", "number output format, one of preserve|exponent|shortest"),
+		decimals:         fs.Int("decimals", -1, "write numbers with this many decimal places, overriding -numbers"),
+		eol:              fs.String("eol", "lf", "line ending of the output, one of lf|crlf"),
+		finalNewline:     fs.Bool("final-newline", true, "end the output with a line ending"),
+		keyCase:          fs.String("key-case", "", "convert object keys to a naming convention, one of camel|pascal|snake|kebab"),
+		renames:          make(map[string]string),
+		redactHash:       fs.Bool("redact-hash", false, "replace the values selected by -redact with hashes instead of \"***\""),
+	}
+	fs.Func("rename-key", "rename object keys given as `old=new`, which -key-case leaves alone (repeatable)", func(s string) error {
+		old, name, ok := strings.Cut(s, "=")
+		if !ok {
+			return errors.New("want old=new")
+		}
+		f.renames[old] = name
+		return nil
+	})
+	fs.Func("redact", "replace the values at this JSON Pointer, or at paths ending with this dotted `pattern` such as password or *.token, with \"***\" (repeatable)", func(s string) error {
+		f.redact = append(f.redact, s)
+		return nil
+	})
+	if pretty {
+		f.indent = fs.Int("indent", 2, "number of spaces per nesting level")
+		f.useTabs = fs.Bool("use-tabs", false, "indent with tabs instead of spaces")
+		f.compactArrays = fs.Bool("compact-arrays", false, "keep arrays of scalars on a single line")
+		f.maxWidth = fs.Int("max-width", 0, "write objects and arrays fitting into this many columns on one line, 0 disables")
+		f.color = fs.String("color", "auto", "colorize the output, one of auto|always|never")
+	}
+	return f
+}
+
+// transform returns the function changing documents before they are
+// written as selected by the flags, or nil if they are kept.
+func (f *styleFlags) transform() (func(*jsonparser.Element), error) {
+	rename, err := f.keyRenamer()
+	if err != nil {
+		return nil, err
+	}
+	var rules []jsonparser.RedactRule
+	for _, pattern := range f.redact {
+		rule := jsonparser.RedactRule{Pattern: pattern}
+		if *f.redactHash {
+			rule.Mode = jsonparser.RedactHash
+		}
+		rules = append(rules, rule)
+	}
+	// report invalid patterns before reading any input
+	null, _ := jsonparser.FromValue(nil)
+	if err := jsonparser.Redact(null, rules); err != nil {
+		return nil, usageErrorf("-redact: %v", err)
+	}
+	if rename == nil && rules == nil {
+		return nil, nil
+	}
+	return func(json *jsonparser.Element) {
+		// patterns name the keys of the input
+		jsonparser.Redact(json, rules)
+		if rename != nil {
+			jsonparser.RenameKeys(json, rename)
+		}
+	}, nil
+}
+
+// keyRenamer returns the function renaming object keys as selected
+// by the flags, or nil if they are kept.
+func (f *styleFlags) keyRenamer() (func(string) string, error) {
+	var c jsonparser.KeyCase
+	switch *f.keyCase {
+	case "":
+	case "camel":
+		c = jsonparser.CamelCase
+	case "pascal":
+		c = jsonparser.PascalCase
+	case "snake":
+		c = jsonparser.SnakeCase
+	case "kebab":
+		c = jsonparser.KebabCase
+	default:
+		return nil, usageErrorf("unsupported key case: %q", *f.keyCase)
+	}
+	if c == 0 && len(f.renames) == 0 {
+		return nil, nil
+	}
+	return func(key string) string {
+		if name, ok := f.renames[key]; ok {
+			return name
+		}
+		if c == 0 {
+			return key
+		}
+		return jsonparser.ConvertKey(key, c)
+	}, nil
+}
+
+// options returns the format options and the layout selected by the flags,
+// except for the final newline. Colors are only used if allowed.
+func (f *styleFlags) options(allowColor bool) ([]jsonparser.FormatOption, jsonparser.IndentOptions, error) {
+	var (
+		opts   []jsonparser.FormatOption
+		layout jsonparser.IndentOptions
+	)
+	switch *f.output {
+	case "json":
+	case "jsonc":
+		opts = append(opts, jsonparser.WithTrailingCommas())
+	case "json5":
+		opts = append(opts,
+			jsonparser.WithBareKeys(), jsonparser.WithSingleQuotedStrings(), jsonparser.WithTrailingCommas())
+	default:
+		return nil, layout, usageErrorf("unsupported output syntax: %q", *f.output)
+	}
+
+	switch *f.numbers {
+	case "preserve":
+	case "exponent":
+		opts = append(opts, jsonparser.WithNumberFormat(jsonparser.NumberNormalExponent))
+	case "shortest":
+		opts = append(opts, jsonparser.WithNumberFormat(jsonparser.NumberShortest))
+	default:
+		return nil, layout, usageErrorf("unsupported number format: %q", *f.numbers)
+	}
+	if *f.decimals >= 0 {
+		opts = append(opts, jsonparser.WithFixedDecimals(*f.decimals))
+	}
+
+	switch *f.eol {
+	case "lf":
+	case "crlf":
+		opts = append(opts, jsonparser.WithLineEnding("\r\n"))
+	default:
+		return nil, layout, usageErrorf("unsupported line ending: %q", *f.eol)
+	}
+
+	if *f.sortKeys {
+		opts = append(opts, jsonparser.WithSortKeys())
+	}
+	if *f.normalizeEscapes {
+		opts = append(opts, jsonparser.WithNormalizeEscapes())
+	}
+	if !f.pretty {
+		return opts, layout, nil
+	}
+
+	colorize, err := useColor(*f.color)
+	if err != nil {
+		return nil, layout, err
+	}
+	if *f.indent < 0 {
+		return nil, layout, usageErrorf("-indent must not be negative")
+	}
+	layout.Indent = strings.Repeat(" ", *f.indent)
+	if *f.useTabs {
+		layout.Indent = "\t"
+	}
+	if *f.compactArrays {
+		opts = append(opts, jsonparser.WithCompactArrays())
+	}
+	if *f.maxWidth > 0 {
+		opts = append(opts, jsonparser.WithMaxWidth(*f.maxWidth))
+	}
+	if colorize && allowColor {
+		opts = append(opts, jsonparser.WithColor())
+	}
+	return opts, layout, nil
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// Formatter writes a parsed document in an output mode.
+// Every formatter is available as a command of its own.
+type Formatter interface {
+	Name() string
+	// Help describes the output in a sentence for the usage text.
+	Help() string
+	// Format writes json with the settings of the job formatting it.
+	Format(w io.Writer, json *jsonparser.Element, s *settings) error
+}
+
+// flagger is implemented by formatters with flags of their own,
+// which set the fields of the settings of the command.
+type flagger interface {
+	Flags(fs *flag.FlagSet, s *settings)
+}
+
+var formatters = make(map[string]Formatter)
+
+// registerFormatter makes f available as an output mode. Formatters are
+// registered from init functions, so that additional modes can be added
+// by files of their own, optionally behind build tags.
+func registerFormatter(f Formatter) {
+	if _, ok := formatters[f.Name()]; ok {
+		panic(fmt.Sprintf("formatter %q registered twice", f.Name()))
+	}
+	formatters[f.Name()] = f
+}
+
+// formatterNames returns the names of all output modes in sorted order.
+func formatterNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// formatterFunc adapts a function to the Formatter interface.
+type formatterFunc struct {
+	name   string
+	help   string
+	format func(w io.Writer, json *jsonparser.Element, s *settings) error
+	// flags registers the flags of the formatter, if not nil.
+	flags func(fs *flag.FlagSet, s *settings)
+}
+
+func (f formatterFunc) Name() string { return f.name }
+
+func (f formatterFunc) Help() string { return f.help }
+
+func (f formatterFunc) Flags(fs *flag.FlagSet, s *settings) {
+	if f.flags != nil {
+		f.flags(fs, s)
+	}
+}
+
+func (f formatterFunc) Format(w io.Writer, json *jsonparser.Element, s *settings) error {
+	return f.format(w, json, s)
+}
+
+// ignoreSettings adapts a function writing documents in a fixed way
+// to the format field of formatterFunc.
+func ignoreSettings(format func(w io.Writer, json *jsonparser.Element) error) func(io.Writer, *jsonparser.Element, *settings) error {
+	return func(w io.Writer, json *jsonparser.Element, _ *settings) error {
+		return format(w, json)
+	}
+}
+
+// settings holds the output settings resolved from the flags,
+// for formatters to read. Every job has its own copy, which is not
+// modified once inputs are processed, so that they can be formatted
+// in parallel.
+type settings struct {
+	// input is the path of the document.
+	input      string
+	formatOpts []jsonparser.FormatOption
+	layout     jsonparser.IndentOptions
+	// transform changes documents before they are written if not nil.
+	transform func(*jsonparser.Element)
+
+	xmlRoot      string
+	xmlItem      string
+	xmlIndent    int
+	csvDelimiter string
+	csvNull      string
+	csvFlatten   bool
+	template     string
+}
+
+// csvOptions returns the options of the csv and tsv modes,
+// separating fields with delimiter.
+func csvOptions(s *settings, delimiter string) ([]jsonparser.CSVOption, error) {
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) {
+		return nil, usageErrorf("invalid csv delimiter: %q", delimiter)
+	}
+	opts := []jsonparser.CSVOption{jsonparser.WithCSVDelimiter(r), jsonparser.WithCSVNull(s.csvNull)}
+	if s.csvFlatten {
+		opts = append(opts, jsonparser.WithCSVFlatten())
+	}
+	return opts, nil
+}
+
+func csvFlags(fs *flag.FlagSet, s *settings) {
+	fs.StringVar(&s.csvNull, "csv-null", "", "text written for nulls")
+	fs.BoolVar(&s.csvFlatten, "csv-flatten", false, "write members of nested objects in dotted columns")
+}
+
+// transformed returns json changed as selected by the style flags.
+// Documents are copied first, as the outputs of eval may share values.
+func (s *settings) transformed(json *jsonparser.Element) *jsonparser.Element {
+	if s.transform == nil {
+		return json
+	}
+	json = json.Clone()
+	s.transform(json)
+	return json
+}
+
+// writeIndented writes the results of conversions to JSON,
+// which have no style flags, indented by two spaces.
+func writeIndented(w io.Writer, json *jsonparser.Element) error {
+	return jsonparser.WritePrettyIndent(w, json, jsonparser.IndentOptions{Indent: "  "}, jsonparser.WithFinalNewline())
+}
+
+func init() {
+	registerFormatter(formatterFunc{
+		name: "ast",
+		help: "Print the syntax tree of documents.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			_, err := fmt.Fprintln(w, jsonparser.ASTString(json))
+			return err
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "pretty",
+		help: "Format documents with indentation.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			return jsonparser.WritePrettyIndent(w, s.transformed(json), s.layout, s.formatOpts...)
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "minify",
+		help: "Format documents without insignificant whitespace.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			return jsonparser.WriteMinified(w, s.transformed(json), s.formatOpts...)
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "flatten",
+		help: "Flatten documents into objects mapping paths like a.b[0] to scalars.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			return writeIndented(w, jsonparser.Flatten(json))
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "unflatten",
+		help: "Rebuild documents flattened by the flatten command.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			doc, err := jsonparser.Unflatten(json)
+			if err != nil {
+				return err
+			}
+			return writeIndented(w, doc)
+		},
+	})
+	registerFormatter(formatterFunc{
+		name:   "canonical",
+		help:   "Write documents in the RFC 8785 canonical form.",
+		format: ignoreSettings(jsonparser.WriteCanonical),
+	})
+	registerFormatter(formatterFunc{
+		name:   "yaml",
+		help:   "Convert documents to YAML.",
+		format: ignoreSettings(jsonparser.WriteYAML),
+	})
+	registerFormatter(formatterFunc{
+		name: "xml",
+		help: "Convert documents to XML.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			if s.xmlIndent < 0 {
+				return usageErrorf("-indent must not be negative")
+			}
+			return jsonparser.WriteXML(w, json, jsonparser.WithXMLRoot(s.xmlRoot),
+				jsonparser.WithXMLItem(s.xmlItem),
+				jsonparser.WithXMLIndent(strings.Repeat(" ", s.xmlIndent)))
+		},
+		flags: func(fs *flag.FlagSet, s *settings) {
+			fs.StringVar(&s.xmlRoot, "xml-root", "root", "name of the document element")
+			fs.StringVar(&s.xmlItem, "xml-item", "item", "name of the elements holding array elements")
+			fs.IntVar(&s.xmlIndent, "indent", 2, "number of spaces per nesting level")
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "csv",
+		help: "Convert arrays of objects to CSV.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			opts, err := csvOptions(s, s.csvDelimiter)
+			if err != nil {
+				return err
+			}
+			return jsonparser.WriteCSV(w, json, opts...)
+		},
+		flags: func(fs *flag.FlagSet, s *settings) {
+			fs.StringVar(&s.csvDelimiter, "csv-delimiter", ",", "field delimiter")
+			csvFlags(fs, s)
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "tsv",
+		help: "Convert arrays of objects to tab-separated values.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			opts, err := csvOptions(s, "\t")
+			if err != nil {
+				return err
+			}
+			return jsonparser.WriteCSV(w, json, opts...)
+		},
+		flags: csvFlags,
+	})
+	registerFormatter(formatterFunc{
+		name: "go",
+		help: "Convert documents to Go composite literals.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			if err := jsonparser.WriteGoLiteral(w, json); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintln(w)
+			return err
+		},
+	})
+	registerFormatter(formatterFunc{
+		name: "html",
+		help: "Render documents as collapsible trees in HTML pages.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			return jsonparser.WriteHTML(w, json, filepath.Base(s.input))
+		},
+	})
+	registerFormatter(formatterFunc{
+		name:   "dot",
+		help:   "Render the syntax tree of documents as Graphviz graphs.",
+		format: ignoreSettings(jsonparser.WriteDOT),
+	})
+	registerFormatter(formatterFunc{
+		name:   "markdown",
+		help:   "Convert arrays of objects to Markdown tables.",
+		format: ignoreSettings(jsonparser.WriteMarkdownTable),
+	})
+	registerFormatter(formatterFunc{
+		name: "template",
+		help: "Execute a text/template with documents.",
+		format: func(w io.Writer, json *jsonparser.Element, s *settings) error {
+			if s.template == "" {
+				return usageErrorf("template requires -t")
+			}
+			t, err := template.New(filepath.Base(s.template)).
+				Funcs(jsonparser.TemplateFuncs(json)).
+				ParseFiles(s.template)
+			if err != nil {
+				return err
+			}
+			return t.Execute(w, jsonparser.ToValue(json))
+		},
+		flags: func(fs *flag.FlagSet, s *settings) {
+			fs.StringVar(&s.template, "t", "", "path to the template `file`")
+		},
+	})
+}
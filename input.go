@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// jsonExtensions are the extensions of the files
+// found by recursive patterns, optionally followed
+// by one of compressedExtensions.
+var jsonExtensions = []string{".json", ".jsonc", ".json5"}
+
+var compressedExtensions = []string{".gz"}
+
+// Magic bytes starting compressed data. zstd is only detected
+// to reject it with a clear error.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// expandInputs returns the paths of the documents named by args: "-" for
+// stdin, which is also read without arguments, plain paths, shell-style
+// glob patterns, and directories or "dir/..." for the files anywhere
+// below dir selected by filter.
+func expandInputs(args []string, filter *fileFilter) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var inputs []string
+	for _, arg := range args {
+		switch {
+		case isURL(arg):
+			inputs = append(inputs, arg)
+		case arg == "..." || strings.HasSuffix(arg, "/..."):
+			root := strings.TrimSuffix(strings.TrimSuffix(arg, "..."), "/")
+			if root == "" {
+				root = "."
+			}
+			paths, err := filter.find(root)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, paths...)
+		case strings.ContainsAny(arg, "*?["):
+			paths, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(paths) == 0 {
+				return nil, fmt.Errorf("no files match %q", arg)
+			}
+			inputs = append(inputs, paths...)
+		default:
+			if fi, err := os.Stat(arg); err == nil && fi.IsDir() {
+				paths, err := filter.find(arg)
+				if err != nil {
+					return nil, err
+				}
+				inputs = append(inputs, paths...)
+				continue
+			}
+			inputs = append(inputs, arg)
+		}
+	}
+	return inputs, nil
+}
+
+// fileFilter selects the files found in directories.
+type fileFilter struct {
+	// include holds shell-style patterns matching the names of
+	// the files to select; files with jsonExtensions by default.
+	include []string
+	// exclude holds .gitignore-style patterns of the files
+	// and directories to skip.
+	exclude []ignorePattern
+}
+
+// addFileFlags registers the -include and -exclude flags.
+func addFileFlags(fs *flag.FlagSet) *fileFilter {
+	f := new(fileFilter)
+	fs.Func("include", "select files in directories whose names match the shell `pattern`, by default *.json, *.jsonc and *.json5 (repeatable)", func(s string) error {
+		if _, err := filepath.Match(s, ""); err != nil {
+			return err
+		}
+		f.include = append(f.include, s)
+		return nil
+	})
+	fs.Func("exclude", "skip files and directories matching the .gitignore-style `pattern` (repeatable)", func(s string) error {
+		p, err := parseIgnorePattern(s)
+		if err != nil {
+			return err
+		}
+		f.exclude = append(f.exclude, p)
+		return nil
+	})
+	return f
+}
+
+// find returns the files below root selected by the filter in lexical order.
+func (f *fileFilter) find(root string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		if rel != "." && f.excluded(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && f.included(d.Name()) {
+			paths = append(paths, file)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+func (f *fileFilter) included(name string) bool {
+	if len(f.include) == 0 {
+		return hasJSONExtension(name)
+	}
+	for _, pattern := range f.include {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded reports whether the slash-separated path relative
+// to the walked directory is excluded; the last matching pattern wins.
+func (f *fileFilter) excluded(rel string, isDir bool) bool {
+	var excluded bool
+	for _, p := range f.exclude {
+		if p.match(rel, isDir) {
+			excluded = !p.negate
+		}
+	}
+	return excluded
+}
+
+// ignorePattern is a pattern of a .gitignore file.
+type ignorePattern struct {
+	// segments are the slash-separated parts of the pattern,
+	// where "**" matches any number of directories.
+	segments []string
+	negate   bool
+	dirOnly  bool
+	// anchored patterns contain a slash other than at the end and match
+	// paths from the walked directory, others match names at any depth.
+	anchored bool
+}
+
+func parseIgnorePattern(s string) (ignorePattern, error) {
+	var p ignorePattern
+	if strings.HasPrefix(s, "!") {
+		p.negate = true
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, "/") {
+		p.dirOnly = true
+		s = strings.TrimRight(s, "/")
+	}
+	if s == "" {
+		return p, errors.New("empty exclude pattern")
+	}
+	p.anchored = strings.Contains(s, "/")
+	p.segments = strings.Split(strings.TrimPrefix(s, "/"), "/")
+	for _, seg := range p.segments {
+		if _, err := filepath.Match(seg, ""); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func (p ignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if !p.anchored {
+		ok, _ := filepath.Match(p.segments[0], path.Base(rel))
+		return ok
+	}
+	return matchSegments(p.segments, strings.Split(rel, "/"))
+}
+
+// matchSegments matches path segments against pattern segments.
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(segments); i >= 0; i-- {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+func hasJSONExtension(path string) bool {
+	for _, ext := range compressedExtensions {
+		path = strings.TrimSuffix(path, ext)
+	}
+	ext := filepath.Ext(path)
+	for _, e := range jsonExtensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// isURL reports whether the input is a URL to download.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// fetch downloads the document at url.
+func (j *job) fetch(url string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, values := range j.header {
+		req.Header[name] = values
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &exitError{code: exitIO, err: fmt.Errorf("GET %s: %s", url, resp.Status)}
+	}
+	if j.maxSize > 0 && resp.ContentLength > j.maxSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s is %d bytes, exceeds -max-size of %d bytes: %w", url, resp.ContentLength, j.maxSize, jsonparser.ErrInputTooLarge)
+	}
+	return resp.Body, nil
+}
+
+// limit returns r failing with jsonparser.ErrInputTooLarge
+// once more than -max-size bytes are read from it.
+func (j *job) limit(r io.ReadCloser, name string) io.ReadCloser {
+	if j.maxSize <= 0 {
+		return r
+	}
+	return &limitReader{ReadCloser: r, name: name, max: j.maxSize, left: j.maxSize}
+}
+
+// limitReader reads at most max bytes from the embedded reader.
+type limitReader struct {
+	io.ReadCloser
+	name      string
+	max, left int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, l.tooLarge()
+	}
+	// read one byte more than allowed to tell a document
+	// of exactly max bytes from a larger one
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	if l.left -= int64(n); l.left < 0 {
+		return n - 1, l.tooLarge()
+	}
+	return n, err
+}
+
+func (l *limitReader) tooLarge() error {
+	return fmt.Errorf("%s exceeds -max-size of %d bytes: %w", l.name, l.max, jsonparser.ErrInputTooLarge)
+}
+
+// compressed reports whether a file is compressed
+// according to its name or its first bytes.
+func compressed(name string, head []byte) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return slices.Contains(compressedExtensions, ext) ||
+		bytes.HasPrefix(head, gzipMagic) || bytes.HasPrefix(head, zstdMagic)
+}
+
+// decompress returns a reader of the decompressed contents of r
+// if it is compressed, and of the contents of r as is otherwise.
+// Only gzip is supported.
+func decompress(r io.ReadCloser, name string) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(zstdMagic))
+	if strings.EqualFold(path.Ext(name), ".zst") || bytes.HasPrefix(head, zstdMagic) {
+		r.Close()
+		return nil, errors.New("zstd compressed input is not supported")
+	}
+	if !compressed(name, head) {
+		return struct {
+			io.Reader
+			io.Closer
+		}{br, r}, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("invalid gzip input: %w", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, r}, nil
+}
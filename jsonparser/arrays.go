@@ -0,0 +1,73 @@
+package jsonparser
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FilterArray returns a new array of the elements of the array el
+// for which pred returns true, in order. The elements are not copied.
+func FilterArray(el *Element, pred func(*Element) bool) (*Element, error) {
+	elements, ok := el.Array()
+	if !ok {
+		return nil, fmt.Errorf("cannot filter %s", el.kind)
+	}
+	kept := []*Element{}
+	for _, e := range elements {
+		if pred(e) {
+			kept = append(kept, e)
+		}
+	}
+	return &Element{kind: ArrayKind, value: kept}, nil
+}
+
+// SortOrder is the direction of SortArray.
+type SortOrder uint8
+
+const (
+	// Ascending sorts the smallest values first.
+	Ascending SortOrder = iota
+	// Descending sorts the largest values first.
+	Descending
+)
+
+// SortArray returns a new array of the elements of the array el sorted
+// by the outputs of the jq-like filter by, such as ".name" or, to sort
+// by several fields, ".last, .first". The outputs are compared in turn
+// as by the filters: null first, then false, true, numbers by value,
+// strings lexically, arrays and objects. Elements comparing equal keep
+// their order, and the elements are not copied.
+func SortArray(el *Element, by string, order SortOrder) (*Element, error) {
+	elements, ok := el.Array()
+	if !ok {
+		return nil, fmt.Errorf("cannot sort %s", el.kind)
+	}
+	f, err := CompileFilter(by)
+	if err != nil {
+		return nil, err
+	}
+	type keyed struct {
+		el  *Element
+		key []*Element
+	}
+	items := make([]keyed, len(elements))
+	for i, e := range elements {
+		key, err := f.Eval(e)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		items[i] = keyed{e, key}
+	}
+	slices.SortStableFunc(items, func(a, b keyed) int {
+		c := slices.CompareFunc(a.key, b.key, jqCompare)
+		if order == Descending {
+			return -c
+		}
+		return c
+	})
+	sorted := make([]*Element, len(items))
+	for i, item := range items {
+		sorted[i] = item.el
+	}
+	return &Element{kind: ArrayKind, value: sorted}, nil
+}
@@ -0,0 +1,153 @@
+package jsonparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Canonical serializes the element as specified by the JSON Canonicalization
+// Scheme (RFC 8785), so that equal documents are serialized to identical
+// bytes, as needed for hashing and signing them.
+func Canonical(e *Element) (string, error) {
+	var sb strings.Builder
+	if err := WriteCanonical(&sb, e); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// WriteCanonical writes the element to w as specified by the JSON
+// Canonicalization Scheme (RFC 8785): without insignificant whitespace,
+// with object members sorted by the UTF-16 code units of their keys,
+// strings escaped minimally and numbers formatted as in ECMAScript.
+//
+// Numbers are converted to IEEE 754 double precision first, which fails
+// for NaN, infinities and numbers out of its range. Unpaired surrogates
+// in strings are replaced with U+FFFD.
+func WriteCanonical(w io.Writer, e *Element) error {
+	var (
+		bw   = bufio.NewWriter(w)
+		walk func(el *Element) error
+	)
+	walk = func(e *Element) error {
+		e.load()
+		switch e.kind {
+		case ArrayKind:
+			bw.WriteRune('[')
+			for i, el := range e.value.([]*Element) {
+				if i != 0 {
+					bw.WriteRune(',')
+				}
+				if err := walk(el); err != nil {
+					return err
+				}
+			}
+			bw.WriteRune(']')
+		case ObjectKind:
+			bw.WriteRune('{')
+			for i, m := range canonicalMembers(e.value.([]Member)) {
+				if i != 0 {
+					bw.WriteRune(',')
+				}
+				bw.WriteRune('"')
+				bw.Write(escapeString(m.key))
+				bw.WriteString(`":`)
+				if err := walk(m.value); err != nil {
+					return err
+				}
+			}
+			bw.WriteRune('}')
+		case StringKind:
+			bw.WriteRune('"')
+			bw.Write(escapeString(unescape(e.value.([]byte))))
+			bw.WriteRune('"')
+		case NumberKind:
+			f, ok := e.Float64()
+			if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+				return fmt.Errorf("number %s cannot be canonicalized", numberText(e.value))
+			}
+			bw.WriteString(es6NumberText(f))
+		case BooleanKind:
+			bw.WriteString(fmt.Sprintf("%v", e.value))
+		case NullKind:
+			bw.WriteString("null")
+		default:
+			panic("unreachable")
+		}
+		return nil
+	}
+
+	if err := walk(e); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+type canonicalMember struct {
+	key   string
+	units []uint16
+	value *Element
+}
+
+// canonicalMembers returns the members with unescaped keys
+// sorted by their UTF-16 code units.
+func canonicalMembers(members []Member) []canonicalMember {
+	sorted := make([]canonicalMember, len(members))
+	for i, m := range members {
+		key := unescape(m.key)
+		sorted[i] = canonicalMember{
+			key:   key,
+			units: utf16.Encode([]rune(key)),
+			value: m.value,
+		}
+	}
+	slices.SortStableFunc(sorted, func(a, b canonicalMember) int {
+		return slices.Compare(a.units, b.units)
+	})
+	return sorted
+}
+
+// es6NumberText formats a finite number like ECMAScript's Number.prototype.toString.
+func es6NumberText(f float64) string {
+	if f == 0 {
+		// including negative zero
+		return "0"
+	}
+
+	var sign string
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// the shortest digits d1...dk of the number d1.d2...dk × 10^(n-1)
+	text := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(text, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	k, n := len(digits), e+1
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	text = digits[:1]
+	if k > 1 {
+		text += "." + digits[1:]
+	}
+	if n-1 >= 0 {
+		return sign + text + "e+" + strconv.Itoa(n-1)
+	}
+	return sign + text + "e" + strconv.Itoa(n-1)
+}
@@ -0,0 +1,62 @@
+package jsonparser
+
+import "unicode/utf8"
+
+// ColumnUnit defines what the columns of positions and errors count.
+type ColumnUnit uint8
+
+const (
+	// ColumnRunes counts Unicode code points, which is the default.
+	ColumnRunes ColumnUnit = iota
+	// ColumnBytes counts bytes of the UTF-8 input.
+	ColumnBytes
+	// ColumnUTF16 counts UTF-16 code units, as used by the Language Server Protocol.
+	ColumnUTF16
+)
+
+// WithColumnUnit sets what the columns of positions and errors count.
+func WithColumnUnit(unit ColumnUnit) Option {
+	return func(c *config) {
+		c.columns.unit = unit
+	}
+}
+
+// WithTabWidth makes a tab advance the column to the next multiple of n,
+// matching what editors display. A value of 0 or less, the default,
+// counts a tab as a single column.
+func WithTabWidth(n int) Option {
+	return func(c *config) {
+		c.columns.tabWidth = n
+	}
+}
+
+type columns struct {
+	unit     ColumnUnit
+	tabWidth int
+}
+
+// advance returns the column following the rune r of the given
+// encoded size that starts at column col.
+func (c columns) advance(col int, r rune, size int) int {
+	switch {
+	case r == '\t' && c.tabWidth > 0:
+		return col + c.tabWidth - col%c.tabWidth
+	case c.unit == ColumnBytes:
+		return col + size
+	case c.unit == ColumnUTF16 && r >= 0x10000:
+		return col + 2
+	default:
+		return col + 1
+	}
+}
+
+// count returns the column following the text b that starts a line.
+func (c columns) count(b []byte) int {
+	var col int
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		col = c.advance(col, r, size)
+		i += size
+	}
+	return col
+}
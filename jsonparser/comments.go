@@ -0,0 +1,74 @@
+package jsonparser
+
+// Comments holds the comments attached to a node when parsing
+// with WithComments. The text of every comment includes its delimiters.
+type Comments struct {
+	// Leading are the comments on the lines before the node.
+	Leading []string
+	// Trailing are the comments following the node on the line where it ends.
+	Trailing []string
+	// Dangling are the comments before the closing bracket of an object
+	// or array that do not belong to any of its children.
+	Dangling []string
+}
+
+// Comments returns the comments attached to the element, or nil if there are none.
+func (e *Element) Comments() *Comments {
+	return e.comments
+}
+
+// Comments returns the comments attached to the member, or nil if there are none.
+func (m Member) Comments() *Comments {
+	return m.comments
+}
+
+func attachComments(c **Comments, leading, trailing, dangling []string) {
+	if len(leading) == 0 && len(trailing) == 0 && len(dangling) == 0 {
+		return
+	}
+	if *c == nil {
+		*c = &Comments{}
+	}
+	(*c).Leading = append((*c).Leading, leading...)
+	(*c).Trailing = append((*c).Trailing, trailing...)
+	(*c).Dangling = append((*c).Dangling, dangling...)
+}
+
+type comment struct {
+	text string
+	line int
+}
+
+// readComment consumes a comment, keeping it until it is attached to a node.
+func (p *parser) readComment() bool {
+	if p.dropComments {
+		return p.skipComment()
+	}
+
+	line := p.r.line
+	start, pin := p.r.mark()
+	defer p.r.release(pin)
+
+	if !p.skipComment() {
+		return false
+	}
+	p.pending = append(p.pending, comment{
+		text: string(p.r.slice(start, p.r.offset)),
+		line: line,
+	})
+	return true
+}
+
+// takeComments removes the pending comments and splits them into those
+// starting on the given line, where the previous node ended, and the rest.
+func (p *parser) takeComments(line int) (sameLine, rest []string) {
+	for _, c := range p.pending {
+		if c.line == line {
+			sameLine = append(sameLine, c.text)
+		} else {
+			rest = append(rest, c.text)
+		}
+	}
+	p.pending = p.pending[:0]
+	return sameLine, rest
+}
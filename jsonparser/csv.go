@@ -0,0 +1,129 @@
+package jsonparser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSVOption configures WriteCSV.
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	delimiter rune
+	null      string
+	flatten   bool
+}
+
+// WithCSVDelimiter sets the field delimiter, ',' by default.
+func WithCSVDelimiter(r rune) CSVOption {
+	return func(c *csvConfig) {
+		c.delimiter = r
+	}
+}
+
+// WithCSVNull sets the text written for nulls, an empty field by default.
+func WithCSVNull(s string) CSVOption {
+	return func(c *csvConfig) {
+		c.null = s
+	}
+}
+
+// WithCSVFlatten writes the members of nested objects in columns of their own,
+// named by the keys leading to them joined with dots, such as "a.b.c".
+func WithCSVFlatten() CSVOption {
+	return func(c *csvConfig) {
+		c.flatten = true
+	}
+}
+
+// WriteCSV writes an array of objects to w as CSV, one row per object.
+// The header holds the keys of all objects in order of their first
+// appearance, and fields of missing keys are left empty. Nested objects
+// and arrays are written as minified JSON unless flattened.
+func WriteCSV(w io.Writer, e *Element, opts ...CSVOption) error {
+	cfg := csvConfig{delimiter: ','}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	header, rows, err := cfg.table(e)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = cfg.delimiter
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	return cw.WriteAll(rows)
+}
+
+// table returns the header and the rows of fields
+// of an array of objects.
+func (cfg csvConfig) table(e *Element) ([]string, [][]string, error) {
+	elements, ok := e.Array()
+	if !ok {
+		return nil, nil, fmt.Errorf("expected array of objects, got %s", e.kind)
+	}
+
+	var (
+		header  []string
+		columns = make(map[string]int)
+		fields  = make([]map[string]string, len(elements))
+	)
+	for i, el := range elements {
+		el.load()
+		if el.kind != ObjectKind {
+			return nil, nil, fmt.Errorf("element %d: expected object, got %s", i, el.kind)
+		}
+		fields[i] = make(map[string]string)
+		cfg.fields(fields[i], "", el, func(column string) {
+			if _, ok := columns[column]; !ok {
+				columns[column] = len(header)
+				header = append(header, column)
+			}
+		})
+	}
+
+	rows := make([][]string, len(fields))
+	for i, f := range fields {
+		rows[i] = make([]string, len(header))
+		for j, column := range header {
+			rows[i][j] = f[column]
+		}
+	}
+	return header, rows, nil
+}
+
+// fields stores the fields of the object e in row,
+// reporting every column to add.
+func (cfg csvConfig) fields(row map[string]string, prefix string, e *Element, add func(string)) {
+	for _, m := range e.value.([]Member) {
+		column := prefix + m.Key()
+		m.value.load()
+		if cfg.flatten && m.value.kind == ObjectKind && len(m.value.value.([]Member)) != 0 {
+			cfg.fields(row, column+".", m.value, add)
+			continue
+		}
+		add(column)
+		row[column] = cfg.text(m.value)
+	}
+}
+
+func (cfg csvConfig) text(e *Element) string {
+	switch e.kind {
+	case ObjectKind, ArrayKind:
+		return Minify(e)
+	case StringKind:
+		return unescape(e.value.([]byte))
+	case NumberKind:
+		return numberText(e.value)
+	case BooleanKind:
+		return fmt.Sprintf("%v", e.value)
+	case NullKind:
+		return cfg.null
+	}
+	panic("unreachable")
+}
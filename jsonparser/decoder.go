@@ -0,0 +1,38 @@
+package jsonparser
+
+import "io"
+
+// Decoder reads a sequence of whitespace-separated JSON values from a reader.
+type Decoder struct {
+	p *parser
+}
+
+// NewDecoder returns a decoder reading from r.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	return &Decoder{p: newStreamParser(r, opts...)}
+}
+
+// More reports whether there is another value to decode.
+func (d *Decoder) More() bool {
+	d.p.eatWhitespace()
+	return !d.p.r.isEOF()
+}
+
+// Decode parses the next value. It returns io.EOF when the input is exhausted.
+// The decoder cannot continue after a syntax error.
+func (d *Decoder) Decode() (*Element, error) {
+	p := d.p
+	p.eatWhitespace()
+	if p.r.isEOF() {
+		if err := p.readErr(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	el, err := p.parseValue()
+	if err := p.readErr(); err != nil {
+		return nil, err
+	}
+	return el, err
+}
@@ -0,0 +1,172 @@
+package jsonparser
+
+import (
+	"slices"
+	"strconv"
+)
+
+// ChangeKind is the kind of a difference found by Diff.
+type ChangeKind uint8
+
+const (
+	// ChangeAdded is a value only in the second document.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is a value only in the first document.
+	ChangeRemoved
+	// ChangeModified is a value differing between the documents.
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a difference between two documents.
+type Change struct {
+	Kind ChangeKind
+	// Path is the JSON Pointer of the value.
+	Path string
+	// Old is the value in the first document, nil if it was added.
+	Old *Element
+	// New is the value in the second document, nil if it was removed.
+	New *Element
+}
+
+// Diff returns the differences between the documents a and b, ignoring
+// the order of object members and the formatting of strings and numbers.
+// Objects and arrays are compared member by member and element by
+// element, and the members are reported in key order.
+// Of duplicate keys, the first one is compared, as by ResolvePointer.
+func Diff(a, b *Element) []Change {
+	var changes []Change
+	diff(a, b, nil, &changes)
+	return changes
+}
+
+// Equal reports whether the documents a and b are equal
+// in the sense of Diff.
+func Equal(a, b *Element) bool {
+	a.load()
+	b.load()
+	if a.kind != b.kind {
+		return false
+	}
+	switch a.kind {
+	case ObjectKind:
+		am, bm := memberMap(a), memberMap(b)
+		if len(am) != len(bm) {
+			return false
+		}
+		for key, av := range am {
+			bv, ok := bm[key]
+			if !ok || !Equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	case ArrayKind:
+		ae, be := a.value.([]*Element), b.value.([]*Element)
+		return slices.EqualFunc(ae, be, Equal)
+	default:
+		return equalScalars(a, b)
+	}
+}
+
+func diff(a, b *Element, tokens []string, changes *[]Change) {
+	a.load()
+	b.load()
+	if a.kind != b.kind || a.kind != ObjectKind && a.kind != ArrayKind {
+		if !Equal(a, b) {
+			*changes = append(*changes, Change{Kind: ChangeModified, Path: joinPointer(tokens), Old: a, New: b})
+		}
+		return
+	}
+	// the tokens of the children share the array of tokens
+	tokens = tokens[:len(tokens):len(tokens)]
+
+	if a.kind == ArrayKind {
+		ae, be := a.value.([]*Element), b.value.([]*Element)
+		for i := range max(len(ae), len(be)) {
+			path := append(tokens, strconv.Itoa(i))
+			switch {
+			case i >= len(ae):
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: joinPointer(path), New: be[i]})
+			case i >= len(be):
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: joinPointer(path), Old: ae[i]})
+			default:
+				diff(ae[i], be[i], path, changes)
+			}
+		}
+		return
+	}
+
+	am, bm := memberMap(a), memberMap(b)
+	keys := make([]string, 0, len(am)+len(bm))
+	for key := range am {
+		keys = append(keys, key)
+	}
+	for key := range bm {
+		if _, ok := am[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		path := append(tokens, key)
+		av, inA := am[key]
+		bv, inB := bm[key]
+		switch {
+		case !inA:
+			*changes = append(*changes, Change{Kind: ChangeAdded, Path: joinPointer(path), New: bv})
+		case !inB:
+			*changes = append(*changes, Change{Kind: ChangeRemoved, Path: joinPointer(path), Old: av})
+		default:
+			diff(av, bv, path, changes)
+		}
+	}
+}
+
+// memberMap returns the values of the members of an object by key,
+// keeping the first of duplicate keys.
+func memberMap(el *Element) map[string]*Element {
+	members := el.value.([]Member)
+	m := make(map[string]*Element, len(members))
+	for _, member := range members {
+		if _, ok := m[member.Key()]; !ok {
+			m[member.Key()] = member.value
+		}
+	}
+	return m
+}
+
+// equalScalars reports whether the scalars a and b of the same kind
+// are equal, comparing the values of strings and numbers.
+func equalScalars(a, b *Element) bool {
+	switch a.kind {
+	case StringKind:
+		as, _ := a.Str()
+		bs, _ := b.Str()
+		return as == bs
+	case NumberKind:
+		ad, aok := a.Decimal()
+		bd, bok := b.Decimal()
+		if aok && bok {
+			return ad.Cmp(bd) == 0
+		}
+		// NaN and infinities
+		return numberText(a.value) == numberText(b.value)
+	case BooleanKind:
+		return a.value == b.value
+	default:
+		return true
+	}
+}
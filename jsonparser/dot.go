@@ -0,0 +1,86 @@
+package jsonparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// dotMaxValue is the number of characters of a scalar
+// shown in the label of its node.
+const dotMaxValue = 40
+
+// ToDOT renders the element tree as a Graphviz DOT graph. Every element
+// is a node labeled with its key or index, its kind and, for scalars,
+// its value, pointing to the nodes of its children.
+func ToDOT(e *Element) string {
+	var sb strings.Builder
+	_ = WriteDOT(&sb, e)
+	return sb.String()
+}
+
+// WriteDOT writes the element tree to w as a Graphviz DOT graph, like ToDOT.
+func WriteDOT(w io.Writer, e *Element) error {
+	var (
+		bw   = bufio.NewWriter(w)
+		id   int
+		walk func(label string, e *Element) int
+	)
+
+	walk = func(label string, e *Element) int {
+		e.load()
+		n := id
+		id++
+
+		lines := []string{e.kind.String()}
+		if label != "" {
+			lines = append([]string{label}, lines...)
+		}
+		switch e.kind {
+		case ObjectKind, ArrayKind:
+		case StringKind:
+			lines = append(lines, `"`+string(e.value.([]byte))+`"`)
+		case NumberKind:
+			lines = append(lines, numberText(e.value))
+		case BooleanKind:
+			lines = append(lines, fmt.Sprint(e.value))
+		}
+		for i, line := range lines {
+			lines[i] = dotEscape(truncate(line, dotMaxValue))
+		}
+		fmt.Fprintf(bw, "  n%d [label=\"%s\"];\n", n, strings.Join(lines, `\n`))
+
+		switch v := e.value.(type) {
+		case []Member:
+			for _, m := range v {
+				fmt.Fprintf(bw, "  n%d -> n%d;\n", n, walk(`"`+string(m.key)+`"`, m.value))
+			}
+		case []*Element:
+			for i, el := range v {
+				fmt.Fprintf(bw, "  n%d -> n%d;\n", n, walk(fmt.Sprintf("[%d]", i), el))
+			}
+		}
+		return n
+	}
+
+	bw.WriteString("digraph ast {\n")
+	bw.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	walk("", e)
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+// truncate shortens s to n characters, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// dotEscape escapes s for a quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
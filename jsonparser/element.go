@@ -0,0 +1,145 @@
+package jsonparser
+
+// Kind is the type of a JSON value.
+type Kind uint8
+
+func (k Kind) String() string {
+	switch k {
+	case ObjectKind:
+		return "object"
+	case ArrayKind:
+		return "array"
+	case StringKind:
+		return "string"
+	case NumberKind:
+		return "number"
+	case BooleanKind:
+		return "boolean"
+	case NullKind:
+		return "null"
+	}
+	panic("unreachable")
+}
+
+const (
+	ObjectKind Kind = iota + 1
+	ArrayKind
+	StringKind
+	NumberKind
+	BooleanKind
+	NullKind
+)
+
+// Position is a location in the source document.
+type Position struct {
+	// Offset is the 0-based byte offset.
+	Offset int
+	// Line and Col are 1-based, Col counts runes unless set otherwise
+	// by WithColumnUnit.
+	Line int
+	Col  int
+}
+
+// Span is the source range of a node. End points just past the node.
+// Nodes that were not produced by the parser have a zero Span.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// Member is a key/value pair of a JSON object.
+type Member struct {
+	key      []byte
+	keySpan  Span
+	value    *Element
+	comments *Comments
+}
+
+// Key returns the unescaped member key.
+func (m Member) Key() string {
+	return unescape(m.key)
+}
+
+// RawKey returns the member key as it appears in the source, without quotes.
+func (m Member) RawKey() []byte {
+	return m.key
+}
+
+// Value returns the member value.
+func (m Member) Value() *Element {
+	return m.value
+}
+
+// KeySpan returns the source range of the quoted key.
+func (m Member) KeySpan() Span {
+	return m.keySpan
+}
+
+// Element is a node of the parsed JSON document.
+type Element struct {
+	kind     Kind
+	value    any
+	span     Span
+	comments *Comments
+}
+
+// Kind returns the kind of the element.
+func (e *Element) Kind() Kind {
+	return e.kind
+}
+
+// Span returns the source range of the element.
+func (e *Element) Span() Span {
+	return e.span
+}
+
+// Value returns the underlying value of the element:
+// []Member for objects, []*Element for arrays, the raw (still escaped)
+// []byte for strings, bool for booleans and nil for null.
+// Numbers are stored according to the NumberMode: the source text
+// as a string by default, or float64, int64 or json.Number.
+func (e *Element) Value() any {
+	e.load()
+	return e.value
+}
+
+// Object returns the members of an object element.
+func (e *Element) Object() ([]Member, bool) {
+	if e.kind != ObjectKind {
+		return nil, false
+	}
+	e.load()
+	members, _ := e.value.([]Member)
+	return members, true
+}
+
+// Array returns the elements of an array element.
+func (e *Element) Array() ([]*Element, bool) {
+	if e.kind != ArrayKind {
+		return nil, false
+	}
+	e.load()
+	elements, _ := e.value.([]*Element)
+	return elements, true
+}
+
+// Str returns the unescaped value of a string element.
+func (e *Element) Str() (string, bool) {
+	if e.kind != StringKind {
+		return "", false
+	}
+	return unescape(e.value.([]byte)), true
+}
+
+// Bool returns the value of a boolean element.
+func (e *Element) Bool() (bool, bool) {
+	if e.kind != BooleanKind {
+		return false, false
+	}
+	return e.value.(bool), true
+}
+
+// IsNull reports whether the element is null.
+func (e *Element) IsNull() bool {
+	return e.kind == NullKind
+}
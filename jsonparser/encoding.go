@@ -0,0 +1,176 @@
+package jsonparser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type encoding uint8
+
+const (
+	encodingUTF8 encoding = iota
+	encodingUTF16BE
+	encodingUTF16LE
+	encodingUTF32BE
+	encodingUTF32LE
+)
+
+// detectEncoding inspects the first bytes of the input and returns
+// its encoding and the length of the byte order mark, if any.
+func detectEncoding(b []byte) (encoding, int) {
+	switch {
+	case bytes.HasPrefix(b, []byte{0x00, 0x00, 0xFE, 0xFF}):
+		return encodingUTF32BE, 4
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE, 0x00, 0x00}):
+		return encodingUTF32LE, 4
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		return encodingUTF8, 3
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return encodingUTF16BE, 2
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return encodingUTF16LE, 2
+	}
+
+	// Without a BOM the encoding is revealed by the pattern of zero bytes,
+	// since a JSON text starts with an ASCII character (RFC 4627).
+	switch {
+	case len(b) >= 4 && b[0] == 0 && b[1] == 0 && b[2] == 0 && b[3] != 0:
+		return encodingUTF32BE, 0
+	case len(b) >= 4 && b[0] != 0 && b[1] == 0 && b[2] == 0 && b[3] == 0:
+		return encodingUTF32LE, 0
+	case len(b) >= 2 && b[0] == 0 && b[1] != 0:
+		return encodingUTF16BE, 0
+	case len(b) >= 2 && b[0] != 0 && b[1] == 0:
+		return encodingUTF16LE, 0
+	}
+	return encodingUTF8, 0
+}
+
+func (enc encoding) unitSize() int {
+	switch enc {
+	case encodingUTF16BE, encodingUTF16LE:
+		return 2
+	case encodingUTF32BE, encodingUTF32LE:
+		return 4
+	}
+	return 1
+}
+
+// toUTF8 strips the BOM of b and transcodes it to UTF-8.
+func toUTF8(b []byte) []byte {
+	enc, bom := detectEncoding(b)
+	if enc == encodingUTF8 {
+		return b[bom:]
+	}
+	out, _ := transcode(nil, b[bom:], enc, true)
+	return out
+}
+
+// transcode appends the UTF-8 encoding of in to out and returns
+// the trailing bytes of in that do not form a complete character yet.
+// If final is set, incomplete trailing bytes are replaced with U+FFFD.
+func transcode(out, in []byte, enc encoding, final bool) ([]byte, []byte) {
+	size := enc.unitSize()
+	for len(in) >= size {
+		var r rune
+		switch enc {
+		case encodingUTF16BE, encodingUTF16LE:
+			r = rune(enc.uint16(in))
+			if utf16.IsSurrogate(r) {
+				if len(in) < 4 {
+					if !final {
+						return out, in
+					}
+					r = utf8.RuneError
+				} else if dec := utf16.DecodeRune(r, rune(enc.uint16(in[2:]))); dec != utf8.RuneError {
+					r = dec
+					in = in[2:]
+				} else {
+					r = utf8.RuneError
+				}
+			}
+		case encodingUTF32BE:
+			r = rune(binary.BigEndian.Uint32(in))
+		case encodingUTF32LE:
+			r = rune(binary.LittleEndian.Uint32(in))
+		}
+		out = utf8.AppendRune(out, r)
+		in = in[size:]
+	}
+
+	if final && len(in) != 0 {
+		out = utf8.AppendRune(out, utf8.RuneError)
+		in = nil
+	}
+	return out, in
+}
+
+func (enc encoding) uint16(b []byte) uint16 {
+	if enc == encodingUTF16BE {
+		return binary.BigEndian.Uint16(b)
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+// decodingReader strips the BOM of the underlying reader
+// and transcodes UTF-16 and UTF-32 input to UTF-8.
+type decodingReader struct {
+	src      io.Reader
+	detected bool
+	enc      encoding
+	in       []byte
+	out      []byte
+	err      error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	if !d.detected {
+		d.detect()
+	}
+
+	for len(d.out) == 0 {
+		if d.enc == encodingUTF8 && len(d.in) != 0 {
+			d.out, d.in = d.in, nil
+			break
+		}
+
+		if d.err != nil {
+			return 0, d.err
+		}
+
+		if d.enc == encodingUTF8 {
+			// nothing to transcode, hand over to the source
+			return d.src.Read(p)
+		}
+
+		buf := make([]byte, readerChunkSize)
+		n, err := d.src.Read(buf)
+		d.in = append(d.in, buf[:n]...)
+		d.err = err
+		d.out, d.in = transcode(d.out, d.in, d.enc, err != nil)
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decodingReader) detect() {
+	d.detected = true
+
+	head := make([]byte, 4)
+	n, err := io.ReadFull(d.src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		d.err = err
+	}
+	head = head[:n]
+
+	enc, bom := detectEncoding(head)
+	d.enc, d.in = enc, head[bom:]
+	if enc != encodingUTF8 {
+		d.out, d.in = transcode(nil, d.in, enc, d.err != nil)
+	}
+}
@@ -0,0 +1,93 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMaxDepth is wrapped by the syntax error returned when the input
+// exceeds the nesting limit set by WithMaxDepth.
+var ErrMaxDepth = errors.New("max depth exceeded")
+
+// ErrDuplicateKey is wrapped by the syntax error returned for a repeated
+// object key with DuplicateKeysReject.
+var ErrDuplicateKey = errors.New("duplicate object key")
+
+// ErrInvalidUTF8 is wrapped by the syntax error returned for invalid
+// UTF-8 in a string with UTF8Reject.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8 byte")
+
+// ErrInexactNumber is wrapped by the syntax error returned for a number
+// that float64 cannot represent with WithExactNumbers.
+var ErrInexactNumber = errors.New("number cannot be represented exactly by float64")
+
+// SyntaxError describes malformed JSON input.
+// Every parse failure caused by the input itself is a *SyntaxError,
+// use errors.As to inspect it.
+type SyntaxError struct {
+	Msg string
+	// Line and Col are 1-based, Offset is the 0-based byte offset
+	// of the offending character or of the end of the input.
+	// Col counts runes unless set otherwise by WithColumnUnit.
+	Line   int
+	Col    int
+	Offset int
+	// Expected and Got are set when a specific token was expected
+	// but something else was found.
+	Expected string
+	Got      string
+
+	err error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error in JSON at line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.err
+}
+
+// SyntaxErrors lists every syntax error found in recovery mode.
+type SyntaxErrors []SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors as *SyntaxError values
+// for errors.Is and errors.As.
+func (e SyntaxErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = &e[i]
+	}
+	return errs
+}
+
+func (p *parser) expectedError(expected string, got rune) error {
+	err := p.syntaxError(
+		fmt.Errorf(
+			"expected: %q, but got: %q",
+			expected, string(got)),
+	).(*SyntaxError)
+	err.Expected = expected
+	err.Got = string(got)
+	return err
+}
+
+func (p *parser) syntaxError(err error) error {
+	pos := p.r.errorPos()
+	return &SyntaxError{
+		Msg:    err.Error(),
+		Line:   pos.Line,
+		Col:    pos.Col,
+		Offset: pos.Offset,
+		err:    err,
+	}
+}
@@ -0,0 +1,40 @@
+package jsonparser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		col    int
+		offset int
+	}{
+		{name: "empty input", input: ``, line: 1, col: 1, offset: 0},
+		{name: "leading bad byte", input: `x`, line: 1, col: 1, offset: 0},
+		{name: "leading bad byte after whitespace", input: "  \n\t?", line: 2, col: 2, offset: 4},
+		{name: "mid-line bad byte", input: `[1,,2]`, line: 1, col: 4, offset: 3},
+		{name: "mismatched bracket", input: `[1}`, line: 1, col: 3, offset: 2},
+		{name: "missing colon", input: "{\n  \"a\" 1}", line: 2, col: 7, offset: 8},
+		{name: "after multi-byte runes", input: `["é", x]`, line: 1, col: 7, offset: 7},
+		{name: "unexpected end of input", input: `[1,`, line: 1, col: 4, offset: 3},
+		{name: "trailing content", input: `{} x`, line: 1, col: 4, offset: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.input)
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("got error %v, want a syntax error", err)
+			}
+			if serr.Line != tt.line || serr.Col != tt.col || serr.Offset != tt.offset {
+				t.Errorf("got line %d, column %d, offset %d, want line %d, column %d, offset %d",
+					serr.Line, serr.Col, serr.Offset, tt.line, tt.col, tt.offset)
+			}
+		})
+	}
+}
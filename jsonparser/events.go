@@ -0,0 +1,103 @@
+package jsonparser
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+// EventKind is the type of a parse event.
+type EventKind uint8
+
+const (
+	ObjectStartEvent EventKind = iota
+	ObjectEndEvent
+	KeyEvent
+	ArrayStartEvent
+	ArrayEndEvent
+	StringEvent
+	NumberEvent
+	BoolEvent
+	NullEvent
+	// ErrorEvent is the last event of a sequence that failed.
+	ErrorEvent
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case ObjectStartEvent:
+		return "object start"
+	case ObjectEndEvent:
+		return "object end"
+	case KeyEvent:
+		return "key"
+	case ArrayStartEvent:
+		return "array start"
+	case ArrayEndEvent:
+		return "array end"
+	case StringEvent:
+		return "string"
+	case NumberEvent:
+		return "number"
+	case BoolEvent:
+		return "bool"
+	case NullEvent:
+		return "null"
+	case ErrorEvent:
+		return "error"
+	}
+	panic("unreachable")
+}
+
+// Event is a single step of parsing a document.
+type Event struct {
+	Kind EventKind
+	// Value is the unescaped key or string, the text of a number,
+	// or "true" or "false".
+	Value string
+	// Err is set for ErrorEvent.
+	Err error
+}
+
+// Events parses a single JSON document read from r and returns its events,
+// as delivered to a Handler by ParseWithHandler. Parsing advances as the
+// sequence is iterated and stops when the loop breaks. A failure ends
+// the sequence with an ErrorEvent.
+func Events(r io.Reader, opts ...Option) iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		err := ParseWithHandler(r, &eventHandler{yield: yield}, opts...)
+		if err != nil && !errors.Is(err, errStopped) {
+			yield(Event{Kind: ErrorEvent, Err: err})
+		}
+	}
+}
+
+// errStopped stops parsing once the consumer of Events breaks out of the loop.
+var errStopped = errors.New("stopped")
+
+type eventHandler struct {
+	yield func(Event) bool
+}
+
+func (h *eventHandler) emit(kind EventKind, value string) error {
+	if !h.yield(Event{Kind: kind, Value: value}) {
+		return errStopped
+	}
+	return nil
+}
+
+func (h *eventHandler) OnObjectStart() error    { return h.emit(ObjectStartEvent, "") }
+func (h *eventHandler) OnObjectEnd() error      { return h.emit(ObjectEndEvent, "") }
+func (h *eventHandler) OnKey(key string) error  { return h.emit(KeyEvent, key) }
+func (h *eventHandler) OnArrayStart() error     { return h.emit(ArrayStartEvent, "") }
+func (h *eventHandler) OnArrayEnd() error       { return h.emit(ArrayEndEvent, "") }
+func (h *eventHandler) OnString(s string) error { return h.emit(StringEvent, s) }
+func (h *eventHandler) OnNumber(n string) error { return h.emit(NumberEvent, n) }
+func (h *eventHandler) OnNull() error           { return h.emit(NullEvent, "") }
+
+func (h *eventHandler) OnBool(b bool) error {
+	if b {
+		return h.emit(BoolEvent, "true")
+	}
+	return h.emit(BoolEvent, "false")
+}
@@ -0,0 +1,228 @@
+package jsonparser
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Flatten returns an object holding the scalars of the document el
+// by their paths, such as {"a.b[0]": 1} for {"a": {"b": [1]}}.
+// Member names follow dots, or are quoted in brackets if they are empty
+// or contain dots, brackets, quotes or backslashes, and array indices
+// are in brackets. Empty objects and arrays are kept as values, and
+// a scalar document is stored under the empty path.
+func Flatten(el *Element) *Element {
+	flat := &Element{kind: ObjectKind, value: []Member{}}
+	flatten(flat, "", el)
+	return flat
+}
+
+func flatten(flat *Element, path string, el *Element) {
+	el.load()
+	switch el.kind {
+	case ObjectKind:
+		members := el.value.([]Member)
+		if len(members) == 0 {
+			break
+		}
+		for _, m := range members {
+			flatten(flat, path+flatName(m.Key(), path == ""), m.value)
+		}
+		return
+	case ArrayKind:
+		elements := el.value.([]*Element)
+		if len(elements) == 0 {
+			break
+		}
+		for i, e := range elements {
+			flatten(flat, path+"["+strconv.Itoa(i)+"]", e)
+		}
+		return
+	}
+	flat.value = append(flat.value.([]Member), Member{key: escapeString(path), value: el})
+}
+
+// flatName returns the path component of a member name.
+func flatName(name string, first bool) string {
+	if name == "" || strings.ContainsAny(name, `.[]"\`) {
+		return `["` + string(escapeString(name)) + `"]`
+	}
+	if first {
+		return name
+	}
+	return "." + name
+}
+
+// Unflatten returns the document whose paths and scalars are the members
+// of the object el, reversing Flatten. Array elements missing between
+// indices are null, and paths must not lead through each other's values.
+func Unflatten(el *Element) (*Element, error) {
+	members, ok := el.Object()
+	if !ok {
+		return nil, fmt.Errorf("cannot unflatten %s", el.kind)
+	}
+	var root *Element
+	for _, m := range members {
+		path := m.Key()
+		tokens, err := parseFlatPath(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tokens {
+			if t.index > maxFlatIndex {
+				return nil, fmt.Errorf("path %q: index %d too large", path, t.index)
+			}
+		}
+		if root, err = unflatten(root, tokens, m.value); err != nil {
+			return nil, fmt.Errorf("path %q: %w", path, err)
+		}
+	}
+	if root == nil {
+		return &Element{kind: ObjectKind, value: []Member{}}, nil
+	}
+	fillGaps(root)
+	return root, nil
+}
+
+// maxFlatIndex bounds the array indices of Unflatten,
+// which fills the gaps before them with nulls.
+const maxFlatIndex = 1<<20 - 1
+
+// flatToken is a component of a flattened path: a member name,
+// or an array index if index is not negative.
+type flatToken struct {
+	name  string
+	index int
+}
+
+// unflatten stores value at the path of tokens below node,
+// which is nil if not created yet, and returns node.
+func unflatten(node *Element, tokens []flatToken, value *Element) (*Element, error) {
+	if len(tokens) == 0 {
+		if node != nil {
+			return nil, fmt.Errorf("value set twice")
+		}
+		return value.Clone(), nil
+	}
+	t := tokens[0]
+	if t.index < 0 {
+		if node == nil {
+			node = &Element{kind: ObjectKind, value: []Member{}}
+		}
+		members, ok := node.value.([]Member)
+		if !ok || node.kind != ObjectKind {
+			return nil, fmt.Errorf("cannot set member %q of %s", t.name, node.kind)
+		}
+		i := slices.IndexFunc(members, func(m Member) bool { return m.Key() == t.name })
+		var child *Element
+		if i >= 0 {
+			child = members[i].value
+		}
+		child, err := unflatten(child, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			node.value = append(members, Member{key: escapeString(t.name), value: child})
+		} else {
+			members[i].value = child
+		}
+		return node, nil
+	}
+
+	if node == nil {
+		node = &Element{kind: ArrayKind, value: []*Element{}}
+	}
+	elements, ok := node.value.([]*Element)
+	if !ok || node.kind != ArrayKind {
+		return nil, fmt.Errorf("cannot set index %d of %s", t.index, node.kind)
+	}
+	for len(elements) <= t.index {
+		elements = append(elements, nil)
+	}
+	child, err := unflatten(elements[t.index], tokens[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	elements[t.index] = child
+	node.value = elements
+	return node, nil
+}
+
+// fillGaps replaces the array elements missing between indices with null.
+func fillGaps(el *Element) {
+	switch v := el.value.(type) {
+	case []*Element:
+		for i, e := range v {
+			if e == nil {
+				v[i] = &Element{kind: NullKind}
+			} else {
+				fillGaps(e)
+			}
+		}
+	case []Member:
+		for _, m := range v {
+			fillGaps(m.value)
+		}
+	}
+}
+
+// parseFlatPath splits a path written by Flatten into its tokens.
+func parseFlatPath(path string) ([]flatToken, error) {
+	var tokens []flatToken
+	bad := func(why string) error {
+		return fmt.Errorf("invalid path %q: %s", path, why)
+	}
+	for rest := path; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, `["`):
+			end := 2
+			for end < len(rest) && rest[end] != '"' {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rest) || !strings.HasPrefix(rest[end+1:], "]") {
+				return nil, bad("unterminated name")
+			}
+			name, err := ParseString(rest[1 : end+1])
+			if err != nil {
+				return nil, bad("invalid quoted name")
+			}
+			s, _ := name.Str()
+			tokens = append(tokens, flatToken{name: s, index: -1})
+			rest = rest[end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, bad("missing ']'")
+			}
+			i, err := arrayIndex(rest[1:end])
+			if err != nil {
+				return nil, bad(err.Error())
+			}
+			tokens = append(tokens, flatToken{index: i})
+			rest = rest[end+1:]
+		default:
+			if len(tokens) > 0 {
+				if rest[0] != '.' {
+					return nil, bad("expected '.' or '['")
+				}
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, bad("empty name")
+			}
+			tokens = append(tokens, flatToken{name: rest[:end], index: -1})
+			rest = rest[end:]
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,677 @@
+package jsonparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FormatOption configures Minify, Pretty and their variants.
+type FormatOption func(*formatConfig)
+
+type formatConfig struct {
+	sortKeys         bool
+	escapeHTML       bool
+	escapeNonASCII   bool
+	normalizeEscapes bool
+	color            bool
+	compactArrays    bool
+	maxWidth         int
+	eol              string
+	finalNewline     bool
+	bareKeys         bool
+	singleQuotes     bool
+	trailingCommas   bool
+	numberFormat     NumberFormat
+	decimalPlaces    int
+}
+
+func newFormatConfig(opts []FormatOption) formatConfig {
+	cfg := formatConfig{eol: "\n", decimalPlaces: -1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithSortKeys emits object members ordered by their unescaped keys,
+// keeping members with the same key in their original order.
+func WithSortKeys() FormatOption {
+	return func(c *formatConfig) {
+		c.sortKeys = true
+	}
+}
+
+// WithEscapeHTML escapes <, > and & in strings as \u003c, \u003e and \u0026,
+// and the line terminators U+2028 and U+2029, like encoding/json does,
+// so that the output can be embedded in HTML script elements.
+func WithEscapeHTML() FormatOption {
+	return func(c *formatConfig) {
+		c.escapeHTML = true
+	}
+}
+
+// WithEscapeNonASCII escapes every non-ASCII character in strings
+// as \uXXXX, using surrogate pairs outside of the Basic Multilingual Plane,
+// so that the output is plain ASCII. Invalid UTF-8 is escaped as \ufffd.
+func WithEscapeNonASCII() FormatOption {
+	return func(c *formatConfig) {
+		c.escapeNonASCII = true
+	}
+}
+
+// WithNormalizeEscapes re-encodes strings with the shortest escaping,
+// so that strings with the same value are always written the same:
+// only quotes, backslashes and control characters are escaped, the latter
+// with their two-character forms where they exist. Unpaired surrogates
+// are replaced with U+FFFD.
+func WithNormalizeEscapes() FormatOption {
+	return func(c *formatConfig) {
+		c.normalizeEscapes = true
+	}
+}
+
+// WithColor highlights keys, strings, numbers and literals
+// with ANSI escape sequences for display in terminals.
+func WithColor() FormatOption {
+	return func(c *formatConfig) {
+		c.color = true
+	}
+}
+
+// WithCompactArrays keeps arrays whose elements are all strings, numbers,
+// booleans or nulls on a single line in pretty output.
+func WithCompactArrays() FormatOption {
+	return func(c *formatConfig) {
+		c.compactArrays = true
+	}
+}
+
+// WithMaxWidth writes objects and arrays on a single line in pretty output
+// if they fit into n characters including indentation, and breaks them
+// into one member or element per line otherwise. Containers with comments
+// are always broken.
+func WithMaxWidth(n int) FormatOption {
+	return func(c *formatConfig) {
+		c.maxWidth = n
+	}
+}
+
+// WithLineEnding sets the line ending of pretty output, "\n" by default.
+// Only "\n" and "\r\n" produce valid JSON.
+func WithLineEnding(eol string) FormatOption {
+	return func(c *formatConfig) {
+		c.eol = eol
+	}
+}
+
+// WithFinalNewline ends the output with a line ending.
+func WithFinalNewline() FormatOption {
+	return func(c *formatConfig) {
+		c.finalNewline = true
+	}
+}
+
+// WithBareKeys writes object keys that are ECMAScript identifier names
+// without quotes, as allowed by JSON5.
+func WithBareKeys() FormatOption {
+	return func(c *formatConfig) {
+		c.bareKeys = true
+	}
+}
+
+// WithSingleQuotedStrings writes keys and strings in single quotes,
+// as allowed by JSON5.
+func WithSingleQuotedStrings() FormatOption {
+	return func(c *formatConfig) {
+		c.singleQuotes = true
+	}
+}
+
+// WithTrailingCommas follows the last member or element of objects and
+// arrays broken into multiple lines with a comma in pretty output,
+// as allowed by JSON5 and some JSONC parsers.
+func WithTrailingCommas() FormatOption {
+	return func(c *formatConfig) {
+		c.trailingCommas = true
+	}
+}
+
+// WithNumberFormat sets how numbers are written, NumberPreserve by default.
+func WithNumberFormat(format NumberFormat) FormatOption {
+	return func(c *formatConfig) {
+		c.numberFormat = format
+	}
+}
+
+// WithFixedDecimals writes numbers with n decimal places, rounding
+// half away from zero, and overrides WithNumberFormat. NaN, infinities
+// and numbers with exponents beyond ±308, the range of float64,
+// are written as decoded.
+func WithFixedDecimals(n int) FormatOption {
+	return func(c *formatConfig) {
+		c.decimalPlaces = n
+	}
+}
+
+// ANSI colors used by WithColor.
+const (
+	colorKey     = "1;34"
+	colorString  = "32"
+	colorNumber  = "36"
+	colorLiteral = "33"
+	colorNull    = "90"
+	colorComment = "2"
+)
+
+// paint wraps s in the given ANSI color if colors are enabled.
+func (cfg formatConfig) paint(color, s string) string {
+	if !cfg.color {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+// scalar returns the text of a string, number, boolean or null element.
+func (cfg formatConfig) scalar(e *Element) string {
+	switch e.kind {
+	case StringKind:
+		return cfg.paint(colorString, cfg.quote(e.value.([]byte)))
+	case NumberKind:
+		return cfg.paint(colorNumber, formatNumber(e.value, cfg.numberFormat, cfg.decimalPlaces))
+	case BooleanKind:
+		return cfg.paint(colorLiteral, fmt.Sprintf("%v", e.value))
+	case NullKind:
+		return cfg.paint(colorNull, "null")
+	default:
+		panic("unreachable")
+	}
+}
+
+// isCompact reports whether the elements of an array
+// are written on a single line.
+func (cfg formatConfig) isCompact(elements []*Element) bool {
+	if !cfg.compactArrays {
+		return false
+	}
+	for _, el := range elements {
+		if el.kind == ObjectKind || el.kind == ArrayKind || el.comments != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// inline returns the single-line text of an element,
+// or false if it contains comments.
+func (cfg formatConfig) inline(e *Element) (string, bool) {
+	e.load()
+	if e.comments != nil && len(e.comments.Dangling) != 0 {
+		return "", false
+	}
+
+	var sb strings.Builder
+	switch e.kind {
+	case ArrayKind:
+		sb.WriteRune('[')
+		for i, el := range e.value.([]*Element) {
+			if el.comments != nil {
+				return "", false
+			}
+			s, ok := cfg.inline(el)
+			if !ok {
+				return "", false
+			}
+			if i != 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(s)
+		}
+		sb.WriteRune(']')
+	case ObjectKind:
+		sb.WriteRune('{')
+		for i, m := range cfg.members(e) {
+			if m.comments != nil {
+				return "", false
+			}
+			s, ok := cfg.inline(m.value)
+			if !ok {
+				return "", false
+			}
+			if i != 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(cfg.paint(colorKey, cfg.key(m.key)))
+			sb.WriteString(": ")
+			sb.WriteString(s)
+		}
+		sb.WriteRune('}')
+	default:
+		return cfg.scalar(e), true
+	}
+	return sb.String(), true
+}
+
+// escape returns the raw contents of a string with the escaping
+// required by the configuration applied.
+func (cfg formatConfig) escape(raw []byte) string {
+	if cfg.normalizeEscapes {
+		raw = escapeString(unescape(raw))
+	}
+	if !cfg.escapeHTML && !cfg.escapeNonASCII {
+		return string(raw)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(raw); {
+		r, size := utf8.DecodeRune(raw[i:])
+		switch {
+		case cfg.escapeHTML && (r == '<' || r == '>' || r == '&' || r == '\u2028' || r == '\u2029'):
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		case cfg.escapeNonASCII && r >= utf8.RuneSelf:
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				fmt.Fprintf(&sb, `\u%04x\u%04x`, r1, r2)
+			} else {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			}
+		default:
+			sb.Write(raw[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// quote returns the raw contents of a string escaped and quoted
+// as required by the configuration.
+func (cfg formatConfig) quote(raw []byte) string {
+	s := cfg.escape(raw)
+	if !cfg.singleQuotes {
+		return `"` + s + `"`
+	}
+
+	var sb strings.Builder
+	sb.WriteRune('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '"':
+			sb.WriteByte('"')
+			i++
+		case c == '\\' && i+1 < len(s):
+			sb.WriteString(s[i : i+2])
+			i++
+		case c == '\'':
+			sb.WriteString(`\'`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteRune('\'')
+	return sb.String()
+}
+
+// key returns the text of an object key as required by the configuration.
+func (cfg formatConfig) key(raw []byte) string {
+	if cfg.bareKeys {
+		if key := unescape(raw); isIdentifierName(key) && (!cfg.escapeNonASCII || isASCII(key)) {
+			return key
+		}
+	}
+	return cfg.quote(raw)
+}
+
+// isIdentifierName reports whether s can be written as an unquoted JSON5 key.
+func isIdentifierName(s string) bool {
+	for i, r := range s {
+		if i == 0 && !isIdentifierStart(r) || !isIdentifierPart(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// members returns the members of an object element in output order.
+func (cfg formatConfig) members(e *Element) []Member {
+	members := e.value.([]Member)
+	if cfg.sortKeys {
+		members = slices.Clone(members)
+		slices.SortStableFunc(members, func(a, b Member) int {
+			return strings.Compare(unescape(a.key), unescape(b.key))
+		})
+	}
+	return members
+}
+
+// ASTString returns a human-readable dump of the element tree.
+func ASTString(el *Element) string {
+	var (
+		indent int
+		sb     strings.Builder
+		walk   func(e *Element)
+	)
+
+	write := func(s string) {
+		sb.WriteString(strings.Repeat(" ", indent))
+		sb.WriteString(s)
+	}
+
+	walk = func(e *Element) {
+		e.load()
+		write(e.kind.String())
+		sb.WriteString(":")
+		if e.kind == ObjectKind || e.kind == ArrayKind {
+			indent++
+			sb.WriteString("\n")
+		}
+
+		switch v := e.value.(type) {
+		case *Element:
+			walk(v)
+		case []*Element:
+			for _, el := range v {
+				walk(el)
+			}
+		case []Member:
+			for _, p := range v {
+				write("key:")
+				sb.WriteString(string(p.key))
+				sb.WriteString("\n")
+				write("value: ")
+				sb.WriteString("\n")
+				indent++
+				walk(p.value)
+				indent--
+			}
+		case bool:
+			sb.WriteString(fmt.Sprintf("%v", e.value))
+		case string, json.Number, int64, float64, *big.Rat:
+			sb.WriteString(numberText(e.value))
+		default:
+			sb.WriteString(fmt.Sprintf("%s", e.value))
+		}
+
+		sb.WriteString("\n")
+		if e.kind == ObjectKind {
+			indent--
+		}
+	}
+
+	walk(el)
+
+	return sb.String()
+}
+
+// Minify serializes the element without insignificant whitespace.
+func Minify(e *Element, opts ...FormatOption) string {
+	var sb strings.Builder
+	_ = WriteMinified(&sb, e, opts...)
+	return sb.String()
+}
+
+// WriteMinified writes the element without insignificant whitespace to w.
+func WriteMinified(w io.Writer, e *Element, opts ...FormatOption) error {
+	var (
+		cfg  = newFormatConfig(opts)
+		bw   = bufio.NewWriter(w)
+		walk func(el *Element)
+	)
+	walk = func(e *Element) {
+		e.load()
+		if e.kind == ObjectKind {
+			bw.WriteRune('{')
+		} else if e.kind == ArrayKind {
+			bw.WriteRune('[')
+		}
+
+		switch e.kind {
+		case ArrayKind:
+			val := e.value.([]*Element)
+			for i, el := range val {
+				walk(el)
+				if i != len(val)-1 {
+					bw.WriteRune(',')
+				}
+			}
+		case ObjectKind:
+			val := cfg.members(e)
+			for i, p := range val {
+				bw.WriteString(cfg.paint(colorKey, cfg.key(p.key)))
+				bw.WriteRune(':')
+				walk(p.value)
+				if i != len(val)-1 {
+					bw.WriteRune(',')
+				}
+			}
+		default:
+			bw.WriteString(cfg.scalar(e))
+		}
+
+		if e.kind == ObjectKind {
+			bw.WriteRune('}')
+		} else if e.kind == ArrayKind {
+			bw.WriteRune(']')
+		}
+	}
+
+	walk(e)
+	if cfg.finalNewline {
+		bw.WriteString(cfg.eol)
+	}
+
+	return bw.Flush()
+}
+
+// Pretty serializes the element using indent spaces per nesting level.
+func Pretty(e *Element, indent int, opts ...FormatOption) string {
+	var sb strings.Builder
+	_ = WritePretty(&sb, e, indent, opts...)
+	return sb.String()
+}
+
+// WritePretty writes the element to w using indent spaces per nesting level.
+// A negative indent is treated as 0.
+func WritePretty(w io.Writer, e *Element, indent int, opts ...FormatOption) error {
+	indent = max(indent, 0)
+	return WritePrettyIndent(w, e, IndentOptions{Indent: strings.Repeat(" ", indent)}, opts...)
+}
+
+// IndentOptions controls the layout of pretty output
+// with the same semantics as json.MarshalIndent.
+type IndentOptions struct {
+	// Prefix begins every line but the first.
+	Prefix string
+	// Indent is repeated once per nesting level.
+	Indent string
+}
+
+// PrettyIndent serializes the element laid out according to indent.
+func PrettyIndent(e *Element, indent IndentOptions, opts ...FormatOption) string {
+	var sb strings.Builder
+	_ = WritePrettyIndent(&sb, e, indent, opts...)
+	return sb.String()
+}
+
+// WritePrettyIndent writes the element to w laid out according to indent.
+func WritePrettyIndent(w io.Writer, e *Element, indent IndentOptions, opts ...FormatOption) error {
+	var (
+		cfg       = newFormatConfig(opts)
+		sb        = bufio.NewWriter(w)
+		walk      func(el *Element)
+		lvl       int
+		ignoreLvl bool
+		started   bool
+		// lead and tail are the widths of the text written
+		// on the same line before and after the next value
+		lead, tail int
+	)
+
+	write := func(s string) {
+		if !ignoreLvl {
+			if started {
+				sb.WriteString(indent.Prefix)
+			}
+			sb.WriteString(strings.Repeat(indent.Indent, lvl))
+		} else {
+			sb.WriteRune(' ')
+		}
+		sb.WriteString(s)
+		ignoreLvl = false
+		started = true
+	}
+
+	writeComments := func(comments []string) {
+		for _, c := range comments {
+			write(cfg.paint(colorComment, c))
+			sb.WriteString(cfg.eol)
+		}
+	}
+
+	leading := func(c *Comments) {
+		if c != nil {
+			writeComments(c.Leading)
+		}
+	}
+
+	trailing := func(c *Comments) {
+		if c != nil {
+			for _, t := range c.Trailing {
+				sb.WriteRune(' ')
+				sb.WriteString(cfg.paint(colorComment, t))
+			}
+		}
+	}
+
+	dangling := func(c *Comments) {
+		if c != nil {
+			lvl++
+			writeComments(c.Dangling)
+			lvl--
+		}
+	}
+
+	// fits reports whether s written as the next value fits into the line width.
+	fits := func(s string) bool {
+		width := lead + utf8.RuneCountInString(s) + tail +
+			lvl*utf8.RuneCountInString(indent.Indent)
+		if started {
+			width += utf8.RuneCountInString(indent.Prefix)
+		}
+		return width <= cfg.maxWidth
+	}
+
+	walk = func(e *Element) {
+		e.load()
+		if cfg.maxWidth > 0 && (e.kind == ArrayKind || e.kind == ObjectKind) {
+			plain := cfg
+			plain.color = false
+			if s, ok := plain.inline(e); ok && fits(s) {
+				if cfg.color {
+					s, _ = cfg.inline(e)
+				}
+				write(s)
+				return
+			}
+		}
+
+		switch e.kind {
+		case ArrayKind:
+			write("[")
+			val := e.value.([]*Element)
+
+			if len(val) == 0 && (e.comments == nil || len(e.comments.Dangling) == 0) {
+				sb.WriteRune(']')
+				return
+			}
+			if cfg.isCompact(val) && (e.comments == nil || len(e.comments.Dangling) == 0) {
+				for i, el := range val {
+					if i != 0 {
+						sb.WriteRune(',')
+					}
+					sb.WriteString(cfg.scalar(el))
+				}
+				sb.WriteRune(']')
+				return
+			}
+
+			sb.WriteString(cfg.eol)
+			lvl++
+
+			for i, el := range val {
+				leading(el.comments)
+				lead, tail = 0, cfg.separatorWidth(i, len(val))
+				walk(el)
+				if i != len(val)-1 || cfg.trailingCommas {
+					sb.WriteRune(',')
+				}
+				trailing(el.comments)
+				sb.WriteString(cfg.eol)
+			}
+			lvl--
+			dangling(e.comments)
+			write("]")
+		case ObjectKind:
+			write("{")
+			val := cfg.members(e)
+			if len(val) == 0 && (e.comments == nil || len(e.comments.Dangling) == 0) {
+				sb.WriteRune('}')
+				return
+			}
+
+			sb.WriteString(cfg.eol)
+			lvl++
+			for i, p := range val {
+				leading(p.comments)
+				key := cfg.key(p.key)
+				write(cfg.paint(colorKey, key))
+				sb.WriteRune(':')
+				ignoreLvl = true
+				lead, tail = utf8.RuneCountInString(key)+2, cfg.separatorWidth(i, len(val))
+				walk(p.value)
+				if i != len(val)-1 || cfg.trailingCommas {
+					sb.WriteRune(',')
+				}
+				trailing(p.comments)
+				sb.WriteString(cfg.eol)
+			}
+			lvl--
+			dangling(e.comments)
+			write("}")
+
+		default:
+			write(cfg.scalar(e))
+		}
+	}
+
+	leading(e.comments)
+	walk(e)
+	trailing(e.comments)
+	if cfg.finalNewline {
+		sb.WriteString(cfg.eol)
+	}
+
+	return sb.Flush()
+}
+
+// separatorWidth returns the width of the comma following
+// the i-th of n members or elements.
+func (cfg formatConfig) separatorWidth(i, n int) int {
+	if i != n-1 || cfg.trailingCommas {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package jsonparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithFixedDecimals(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		places int
+		want   string
+	}{
+		{name: "rounds half away from zero", input: `[1.005, -2.5, 0.125]`, places: 2, want: `[1.01,-2.50,0.13]`},
+		{name: "no decimal places", input: `[2.5, -2.5, 7]`, places: 0, want: `[3,-3,7]`},
+		{name: "exponents within the range of float64", input: `[1.5e3, 25e-3, 1e308]`, places: 1, want: `[1500.0,0.0,1` + strings.Repeat("0", 308) + `.0]`},
+		{name: "exponents beyond the range of float64", input: `[1e309, 1e-309, 1e10000]`, places: 2, want: `[1e309,1e-309,1e10000]`},
+		{name: "non-finite numbers", input: `[NaN, -Infinity]`, places: 2, want: `[NaN,-Infinity]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input, WithNonFiniteNumbers())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Minify(doc, WithFixedDecimals(tt.places)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package jsonparser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Result is a value found by Get, which may not exist.
+// Its accessors convert the value like GJSON and return
+// zero values if it does not exist.
+type Result struct {
+	el *Element
+}
+
+// Get returns the value at a dotted path like "friends.#.first", in the
+// syntax of GJSON. The components of the path are member names or array
+// indices. In names, "*" matches any characters and "?" a single one,
+// selecting the first matching member, and "\" escapes the next
+// character, such as a dot. On arrays, "#" is the number of elements,
+// or when followed by more components, the array of the values they
+// select from every element.
+func (e *Element) Get(path string) Result {
+	return Result{getPath(e, splitGetPath(path))}
+}
+
+// Get returns the value at path below the result, as Element.Get.
+func (r Result) Get(path string) Result {
+	if r.el == nil {
+		return r
+	}
+	return r.el.Get(path)
+}
+
+// Exists reports whether the value exists.
+func (r Result) Exists() bool {
+	return r.el != nil
+}
+
+// Element returns the value, or nil if it does not exist.
+func (r Result) Element() *Element {
+	return r.el
+}
+
+// Kind returns the kind of the value, or 0 if it does not exist.
+func (r Result) Kind() Kind {
+	if r.el == nil {
+		return 0
+	}
+	return r.el.Kind()
+}
+
+// Str returns the value of strings, the minified JSON of other values,
+// and "" for null.
+func (r Result) Str() string {
+	switch {
+	case r.el == nil || r.el.IsNull():
+		return ""
+	case r.el.Kind() == StringKind:
+		s, _ := r.el.Str()
+		return s
+	}
+	return Minify(r.el)
+}
+
+// Int returns numbers truncated to integers, the integer parsed
+// from strings, and 1 for true.
+func (r Result) Int() int64 {
+	switch r.Kind() {
+	case NumberKind:
+		if n, ok := r.el.Int64(); ok {
+			return n
+		}
+		f, _ := r.el.Float64()
+		return int64(math.Trunc(f))
+	case StringKind:
+		s, _ := r.el.Str()
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		f, _ := strconv.ParseFloat(s, 64)
+		return int64(math.Trunc(f))
+	case BooleanKind:
+		if b, _ := r.el.Bool(); b {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Float returns the value of numbers, the number parsed from strings,
+// and 1 for true.
+func (r Result) Float() float64 {
+	switch r.Kind() {
+	case NumberKind:
+		f, _ := r.el.Float64()
+		return f
+	case StringKind:
+		s, _ := r.el.Str()
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	case BooleanKind:
+		if b, _ := r.el.Bool(); b {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Bool returns the value of booleans, the boolean parsed from strings,
+// and whether numbers are not zero.
+func (r Result) Bool() bool {
+	switch r.Kind() {
+	case BooleanKind:
+		b, _ := r.el.Bool()
+		return b
+	case StringKind:
+		s, _ := r.el.Str()
+		b, _ := strconv.ParseBool(s)
+		return b
+	case NumberKind:
+		return r.Float() != 0
+	}
+	return false
+}
+
+// Array returns the elements of arrays, nil for null and values that do
+// not exist, and a single result for other values.
+func (r Result) Array() []Result {
+	if r.el == nil || r.el.IsNull() {
+		return nil
+	}
+	elements, ok := r.el.Array()
+	if !ok {
+		return []Result{r}
+	}
+	results := make([]Result, len(elements))
+	for i, el := range elements {
+		results[i] = Result{el}
+	}
+	return results
+}
+
+// splitGetPath splits a path at the dots that are not escaped,
+// keeping the escapes in the components.
+func splitGetPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	var parts []string
+	start := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '.':
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// getPath returns the value selected by the components of a path
+// from el, or nil.
+func getPath(el *Element, parts []string) *Element {
+	if len(parts) == 0 {
+		return el
+	}
+	part, rest := parts[0], parts[1:]
+	if elements, ok := el.Array(); ok {
+		if part == "#" {
+			if len(rest) == 0 {
+				return intElement(len(elements))
+			}
+			values := []*Element{}
+			for _, e := range elements {
+				if v := getPath(e, rest); v != nil {
+					values = append(values, v)
+				}
+			}
+			return &Element{kind: ArrayKind, value: values}
+		}
+		if part == "" || strings.IndexFunc(part, notDigit) >= 0 {
+			return nil
+		}
+		i, err := strconv.Atoi(part)
+		if err != nil || i >= len(elements) {
+			return nil
+		}
+		return getPath(elements[i], rest)
+	}
+	members, _ := el.Object()
+	for _, m := range members {
+		if matchKey(part, m.Key()) {
+			return getPath(m.value, rest)
+		}
+	}
+	return nil
+}
+
+func notDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// matchKey reports whether key matches pattern, in which "*" matches
+// any characters, "?" a single character, and "\" escapes the next one.
+func matchKey(pattern, key string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			for i := 0; ; {
+				if matchKey(pattern[1:], key[i:]) {
+					return true
+				}
+				if i == len(key) {
+					return false
+				}
+				_, n := utf8.DecodeRuneInString(key[i:])
+				i += n
+			}
+		case '?':
+			if key == "" {
+				return false
+			}
+			_, n := utf8.DecodeRuneInString(key)
+			pattern, key = pattern[1:], key[n:]
+			continue
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+		}
+		_, n := utf8.DecodeRuneInString(pattern)
+		if !strings.HasPrefix(key, pattern[:n]) {
+			return false
+		}
+		pattern, key = pattern[n:], key[n:]
+	}
+	return key == ""
+}
@@ -0,0 +1,119 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GetPath returns the value at the JSON Pointer ptr in the document b
+// without building elements for the rest of the document. Values before
+// the one looked for are skipped without being decoded or validated,
+// and scanning stops once it was parsed.
+func GetPath(b []byte, ptr string, opts ...Option) (*Element, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newParser(b, opts...)
+	p.dropComments = true
+	p.eatWhitespace()
+	for i, t := range tokens {
+		if err := p.seek(t); err != nil {
+			var se *SyntaxError
+			if errors.As(err, &se) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("cannot resolve %q: %w", joinPointer(tokens[:i+1]), err)
+		}
+		p.eatWhitespace()
+	}
+	p.dropComments = false
+
+	el, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return el, nil
+}
+
+// seek advances to the value of the member or element referenced by token
+// in the object or array at the current position.
+func (p *parser) seek(token string) error {
+	if r, _ := p.r.peek(); r != '{' && r != '[' {
+		el, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%s has no children", el.kind)
+	}
+
+	if p.r.read() == '{' {
+		return p.seekMember(token)
+	}
+
+	idx, err := arrayIndex(token)
+	if err != nil {
+		return err
+	}
+	return p.seekElement(idx)
+}
+
+func (p *parser) seekMember(token string) error {
+	p.eatWhitespace()
+	for n := 0; ; n++ {
+		if r, _ := p.r.peek(); r == '}' || p.r.isEOF() {
+			return fmt.Errorf("member %q not found", token)
+		}
+		if n != 0 {
+			if err := p.expectComma(); err != nil {
+				return err
+			}
+			if r, _ := p.r.peek(); r == '}' {
+				return fmt.Errorf("member %q not found", token)
+			}
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return p.syntaxError(fmt.Errorf("expected object member"))
+		}
+		p.eatWhitespace()
+		if r := p.r.read(); r != ':' {
+			return p.expectedError(":", r)
+		}
+		p.eatWhitespace()
+
+		if unescape(key) == token {
+			return nil
+		}
+		p.skipValue()
+		p.eatWhitespace()
+	}
+}
+
+func (p *parser) seekElement(idx int) error {
+	p.eatWhitespace()
+	for i := 0; ; i++ {
+		if r, _ := p.r.peek(); r == ']' || p.r.isEOF() {
+			return fmt.Errorf("index %d out of range", idx)
+		}
+		if i != 0 {
+			if err := p.expectComma(); err != nil {
+				return err
+			}
+			if r, _ := p.r.peek(); r == ']' {
+				return fmt.Errorf("index %d out of range", idx)
+			}
+		}
+
+		if i == idx {
+			return nil
+		}
+		p.skipValue()
+		p.eatWhitespace()
+	}
+}
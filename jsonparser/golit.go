@@ -0,0 +1,109 @@
+package jsonparser
+
+import (
+	"bytes"
+	"errors"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ToGoLiteral converts the element to a Go composite literal formatted
+// like gofmt does, to be pasted into Go source. Objects become
+// map[string]any, arrays []any and nulls nil.
+//
+// Integers that do not fit into an int are converted to float64,
+// NaN and infinities use the functions of package math.
+func ToGoLiteral(e *Element) string {
+	var sb strings.Builder
+	_ = WriteGoLiteral(&sb, e)
+	return sb.String()
+}
+
+// WriteGoLiteral writes the element to w as a Go composite literal, like ToGoLiteral.
+func WriteGoLiteral(w io.Writer, e *Element) error {
+	var (
+		bw   bytes.Buffer
+		walk func(e *Element, lvl int)
+	)
+	walk = func(e *Element, lvl int) {
+		e.load()
+		switch e.kind {
+		case ObjectKind:
+			members := e.value.([]Member)
+			bw.WriteString("map[string]any{")
+			for _, m := range members {
+				bw.WriteRune('\n')
+				bw.WriteString(strings.Repeat("\t", lvl+1))
+				bw.WriteString(strconv.Quote(m.Key()))
+				bw.WriteString(": ")
+				walk(m.value, lvl+1)
+				bw.WriteRune(',')
+			}
+			if len(members) != 0 {
+				bw.WriteRune('\n')
+				bw.WriteString(strings.Repeat("\t", lvl))
+			}
+			bw.WriteRune('}')
+		case ArrayKind:
+			elements := e.value.([]*Element)
+			bw.WriteString("[]any{")
+			for _, el := range elements {
+				bw.WriteRune('\n')
+				bw.WriteString(strings.Repeat("\t", lvl+1))
+				walk(el, lvl+1)
+				bw.WriteRune(',')
+			}
+			if len(elements) != 0 {
+				bw.WriteRune('\n')
+				bw.WriteString(strings.Repeat("\t", lvl))
+			}
+			bw.WriteRune('}')
+		case StringKind:
+			bw.WriteString(strconv.Quote(unescape(e.value.([]byte))))
+		case NumberKind:
+			bw.WriteString(goNumber(numberText(e.value)))
+		case BooleanKind:
+			bw.WriteString(strconv.FormatBool(e.value.(bool)))
+		case NullKind:
+			bw.WriteString("nil")
+		default:
+			panic("unreachable")
+		}
+	}
+
+	// gofmt aligns the values of consecutive members,
+	// which takes formatting the literal as part of a file
+	const prefix = "package p\n\nvar _ = "
+	bw.WriteString(prefix)
+	walk(e, 0)
+	src, err := format.Source(bw.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes.TrimSuffix(src[len(prefix):], []byte("\n")))
+	return err
+}
+
+// goNumber returns a Go expression for the number text
+// that compiles into a value of type int or float64.
+func goNumber(text string) string {
+	if _, err := strconv.Atoi(text); err == nil {
+		return text
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	switch {
+	case text == "NaN":
+		return "math.NaN()"
+	case errors.Is(err, strconv.ErrRange) && f > 0, text == "Infinity":
+		return "math.Inf(1)"
+	case errors.Is(err, strconv.ErrRange) && f < 0, text == "-Infinity":
+		return "math.Inf(-1)"
+	case !strings.ContainsAny(text, ".eE"):
+		// an untyped integer constant would overflow int
+		return "float64(" + text + ")"
+	}
+	return text
+}
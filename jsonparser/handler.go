@@ -0,0 +1,241 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Handler receives the events of ParseWithHandler in document order.
+// Returning an error from any method stops parsing with that error.
+type Handler interface {
+	OnObjectStart() error
+	OnObjectEnd() error
+	// OnKey receives the unescaped key of an object member,
+	// followed by the events of its value.
+	OnKey(key string) error
+	OnArrayStart() error
+	OnArrayEnd() error
+	// OnString receives the unescaped value of a string.
+	OnString(s string) error
+	// OnNumber receives the text of a number as formatted by Minify.
+	OnNumber(n string) error
+	OnBool(b bool) error
+	OnNull() error
+}
+
+// BaseHandler implements Handler with no-op methods and is meant to be
+// embedded into handlers that only care about some of the events.
+type BaseHandler struct{}
+
+func (BaseHandler) OnObjectStart() error  { return nil }
+func (BaseHandler) OnObjectEnd() error    { return nil }
+func (BaseHandler) OnKey(string) error    { return nil }
+func (BaseHandler) OnArrayStart() error   { return nil }
+func (BaseHandler) OnArrayEnd() error     { return nil }
+func (BaseHandler) OnString(string) error { return nil }
+func (BaseHandler) OnNumber(string) error { return nil }
+func (BaseHandler) OnBool(bool) error     { return nil }
+func (BaseHandler) OnNull() error         { return nil }
+
+// rawHandler is implemented by internal handlers that receive keys
+// and strings as they appear in the input, without unescaping them.
+type rawHandler interface {
+	onRawKey(raw []byte) error
+	onRawString(raw []byte) error
+}
+
+// ParseWithHandler parses a single JSON document read from r, calling h
+// for every value instead of building an element tree, so memory use does
+// not grow with the size of the document. Syntax errors are detected as
+// the input is read, after the events for the preceding values were delivered.
+//
+// Duplicate keys are reported as they appear regardless of
+// WithDuplicateKeyPolicy. Recovery mode and the HJSON dialect are not supported.
+func ParseWithHandler(r io.Reader, h Handler, opts ...Option) error {
+	p := newStreamParser(r, opts...)
+	if p.cfg.dialect != DialectJSON {
+		return errors.New("handler parsing supports the JSON dialect only")
+	}
+	p.cfg.recovery = false
+	p.dropComments = true
+
+	err := p.handleRoot(h)
+	if err := p.readErr(); err != nil {
+		return err
+	}
+	return err
+}
+
+func (p *parser) handleRoot(h Handler) error {
+	p.eatWhitespace()
+	if err := p.handleValue(h); err != nil {
+		return err
+	}
+	p.eatWhitespace()
+	if !p.r.isEOF() {
+		return p.expectedError("eof", p.r.read())
+	}
+	return nil
+}
+
+func (p *parser) handleValue(h Handler) error {
+	switch r, _ := p.r.peek(); r {
+	case '{':
+		p.r.read()
+		return p.handleObject(h)
+	case '[':
+		p.r.read()
+		return p.handleArray(h)
+	}
+
+	el, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	return handleElement(h, el)
+}
+
+// handleElement calls h for the element tree el. Besides scalars,
+// these are containers produced by literal hooks.
+func handleElement(h Handler, el *Element) error {
+	switch el.kind {
+	case ObjectKind:
+		if err := h.OnObjectStart(); err != nil {
+			return err
+		}
+		members, _ := el.Object()
+		for _, m := range members {
+			if err := handleKey(h, m.key); err != nil {
+				return err
+			}
+			if err := handleElement(h, m.value); err != nil {
+				return err
+			}
+		}
+		return h.OnObjectEnd()
+	case ArrayKind:
+		if err := h.OnArrayStart(); err != nil {
+			return err
+		}
+		elements, _ := el.Array()
+		for _, e := range elements {
+			if err := handleElement(h, e); err != nil {
+				return err
+			}
+		}
+		return h.OnArrayEnd()
+	case StringKind:
+		if rh, ok := h.(rawHandler); ok {
+			return rh.onRawString(el.value.([]byte))
+		}
+		return h.OnString(unescape(el.value.([]byte)))
+	case NumberKind:
+		return h.OnNumber(numberText(el.value))
+	case BooleanKind:
+		return h.OnBool(el.value.(bool))
+	case NullKind:
+		return h.OnNull()
+	default:
+		panic("unreachable")
+	}
+}
+
+func handleKey(h Handler, raw []byte) error {
+	if rh, ok := h.(rawHandler); ok {
+		return rh.onRawKey(raw)
+	}
+	return h.OnKey(unescape(raw))
+}
+
+func (p *parser) handleObject(h Handler) error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer p.leave()
+
+	if err := h.OnObjectStart(); err != nil {
+		return err
+	}
+	p.eatWhitespace()
+
+	for n := 0; !p.r.isEOF(); n++ {
+		if r, _ := p.r.peek(); r == '}' {
+			break
+		}
+		if n != 0 {
+			if err := p.expectComma(); err != nil {
+				return err
+			}
+			if p.isTrailingComma('}') {
+				break
+			}
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			if n != 0 {
+				return p.syntaxError(fmt.Errorf("expected object member"))
+			}
+			break
+		}
+		if err := handleKey(h, key); err != nil {
+			return err
+		}
+
+		p.eatWhitespace()
+		if r := p.r.read(); r != ':' {
+			return p.expectedError(":", r)
+		}
+		p.eatWhitespace()
+
+		if err := p.handleValue(h); err != nil {
+			return err
+		}
+		p.eatWhitespace()
+	}
+
+	if err := p.closeContainer('}'); err != nil {
+		return err
+	}
+	return h.OnObjectEnd()
+}
+
+func (p *parser) handleArray(h Handler) error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer p.leave()
+
+	if err := h.OnArrayStart(); err != nil {
+		return err
+	}
+	p.eatWhitespace()
+
+	for n := 0; !p.r.isEOF(); n++ {
+		if r, _ := p.r.peek(); r == ']' {
+			break
+		}
+		if n != 0 {
+			if err := p.expectComma(); err != nil {
+				return err
+			}
+			if p.isTrailingComma(']') {
+				break
+			}
+		}
+
+		if err := p.handleValue(h); err != nil {
+			return err
+		}
+		p.eatWhitespace()
+	}
+
+	if err := p.closeContainer(']'); err != nil {
+		return err
+	}
+	return h.OnArrayEnd()
+}
@@ -0,0 +1,371 @@
+package jsonparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect selects the syntax accepted by the parser.
+type Dialect uint8
+
+const (
+	// DialectJSON is strict RFC 8259 JSON, which is the default.
+	DialectJSON Dialect = iota
+	// DialectHJSON is the human-friendly HJSON syntax (https://hjson.github.io):
+	// comments, optional commas and root braces, quoteless keys and strings,
+	// single-quoted and triple-quoted multiline strings.
+	DialectHJSON
+)
+
+// WithDialect sets the syntax accepted by Parse and Parser.
+func WithDialect(d Dialect) Option {
+	return func(c *config) {
+		c.dialect = d
+	}
+}
+
+func (p *parser) parseHJSONRoot() (*Element, error) {
+	p.hjsonSkip()
+
+	start, pin := p.r.mark()
+	defer p.r.release(pin)
+	line, col := p.r.line, p.r.col
+
+	// A root object may omit its braces, which is only known for sure
+	// once it was parsed successfully.
+	if r, _ := p.r.peek(); r != '{' && r != '[' {
+		pos := p.r.pos()
+		if el, err := p.parseHJSONObject(true); err == nil {
+			el.span = Span{Start: pos, End: p.r.pos()}
+			return el, nil
+		}
+		p.r.offset, p.r.line, p.r.col = start, line, col
+	}
+
+	root, err := p.parseHJSONValue()
+	if err != nil {
+		return nil, err
+	}
+	p.hjsonSkip()
+	if !p.r.isEOF() {
+		return nil, p.expectedError("eof", p.r.read())
+	}
+	return root, nil
+}
+
+func (p *parser) parseHJSONValue() (el *Element, err error) {
+	if err := p.checkContext(); err != nil {
+		return nil, err
+	}
+
+	start := p.r.pos()
+	r, _ := p.r.peek()
+	switch {
+	case r == '{':
+		p.r.read()
+		el, err = p.parseHJSONObject(false)
+	case r == '[':
+		p.r.read()
+		el, err = p.parseHJSONArray()
+	case r == '"':
+		p.r.read()
+		el, err = p.parseString()
+	case r == '\'' && p.r.peekByte(1) == '\'' && p.r.peekByte(2) == '\'':
+		el, err = p.parseHJSONMultiline()
+	case r == '\'':
+		p.r.read()
+		el, err = p.parseSingleQuotedString()
+	case isHJSONPunctuator(r) || p.r.isEOF():
+		return nil, p.syntaxError(fmt.Errorf("unexpected token: %q", p.r.read()))
+	default:
+		el, err = p.parseQuoteless()
+	}
+	if err != nil {
+		return nil, err
+	}
+	el.span = Span{Start: start, End: p.r.pos()}
+	return el, nil
+}
+
+func (p *parser) parseHJSONObject(braceless bool) (*Element, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	var members []Member
+	for {
+		p.hjsonSkip()
+		if p.r.isEOF() {
+			if braceless {
+				break
+			}
+			return nil, p.expectedError("}", p.r.read())
+		}
+		if r, _ := p.r.peek(); r == '}' && !braceless {
+			p.r.read()
+			break
+		}
+
+		keyStart := p.r.pos()
+		key, err := p.parseHJSONKey()
+		if err != nil {
+			return nil, err
+		}
+		keySpan := Span{Start: keyStart, End: p.r.pos()}
+
+		p.hjsonSkip()
+		if r := p.r.read(); r != ':' {
+			return nil, p.expectedError(":", r)
+		}
+		p.hjsonSkip()
+
+		val, err := p.parseHJSONValue()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, Member{key: key, keySpan: keySpan, value: val})
+
+		p.hjsonSkip()
+		if r, _ := p.r.peek(); r == ',' {
+			p.r.read()
+		}
+	}
+
+	return &Element{
+		kind:  ObjectKind,
+		value: members,
+	}, nil
+}
+
+func (p *parser) parseHJSONArray() (*Element, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	var elements []*Element
+	for {
+		p.hjsonSkip()
+		if p.r.isEOF() {
+			return nil, p.expectedError("]", p.r.read())
+		}
+		if r, _ := p.r.peek(); r == ']' {
+			p.r.read()
+			break
+		}
+
+		el, err := p.parseHJSONValue()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, el)
+
+		p.hjsonSkip()
+		if r, _ := p.r.peek(); r == ',' {
+			p.r.read()
+		}
+	}
+
+	return &Element{
+		kind:  ArrayKind,
+		value: elements,
+	}, nil
+}
+
+// parseHJSONKey parses a quoted or quoteless key and returns it escaped.
+func (p *parser) parseHJSONKey() ([]byte, error) {
+	switch r, _ := p.r.peek(); r {
+	case '"':
+		p.r.read()
+		return p.parseRawString()
+	case '\'':
+		p.r.read()
+		s, err := p.parseSingleQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return escapeString(s), nil
+	}
+
+	var sb strings.Builder
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		if r == ':' || isWhitespace(r) {
+			break
+		}
+		if isHJSONPunctuator(r) {
+			return nil, p.syntaxError(fmt.Errorf("unexpected %q in quoteless key", r))
+		}
+		sb.WriteRune(p.r.read())
+	}
+	if sb.Len() == 0 {
+		return nil, p.syntaxError(fmt.Errorf("expected object member"))
+	}
+	return escapeString(sb.String()), nil
+}
+
+// parseQuoteless parses a value without quotes. It is a literal or a number
+// if the text up to a delimiter forms one, otherwise a string extending
+// to the end of the line.
+func (p *parser) parseQuoteless() (*Element, error) {
+	var sb strings.Builder
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		if r == '\n' || r == '\r' {
+			break
+		}
+
+		isDelimiter := r == ',' || r == ']' || r == '}' || r == '#' ||
+			r == '/' && (p.r.peekByte(1) == '/' || p.r.peekByte(1) == '*')
+		if isDelimiter {
+			if el := p.quotelessLiteral(strings.TrimSpace(sb.String())); el != nil {
+				return el, nil
+			}
+		}
+		sb.WriteRune(p.r.read())
+	}
+
+	text := strings.TrimSpace(sb.String())
+	if el := p.quotelessLiteral(text); el != nil {
+		return el, nil
+	}
+	return &Element{kind: StringKind, value: escapeString(text)}, nil
+}
+
+func (p *parser) quotelessLiteral(s string) *Element {
+	switch s {
+	case "true":
+		return &Element{kind: BooleanKind, value: true}
+	case "false":
+		return &Element{kind: BooleanKind, value: false}
+	case "null":
+		return &Element{kind: NullKind}
+	}
+
+	if s == "" || s[0] != '-' && !isDigit(rune(s[0])) {
+		return nil
+	}
+	sub := &parser{r: reader{s: []byte(s), line: 1, pin: -1}, cfg: p.cfg}
+	el, err := sub.parseNumber(sub.r.read())
+	if err != nil || !sub.r.isEOF() {
+		return nil
+	}
+	return el
+}
+
+// parseSingleQuoted parses the rest of a single-quoted string and returns its unescaped value.
+func (p *parser) parseSingleQuoted() (string, error) {
+	var sb strings.Builder
+	for {
+		if p.r.isEOF() {
+			return "", p.expectedError("'", p.r.read())
+		}
+
+		r := p.r.read()
+		switch {
+		case r == '\'':
+			return sb.String(), nil
+		case isSpecialCharacter(r) && !p.cfg.controlChars:
+			return "", p.syntaxError(fmt.Errorf("unescaped special caharacter %q", r))
+		case r != '\\':
+			sb.WriteRune(r)
+			continue
+		}
+
+		switch e := p.r.read(); e {
+		case '\'':
+			sb.WriteRune('\'')
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			sb.WriteString(unescape([]byte{'\\', byte(e)}))
+		case 'u':
+			raw := []byte{'\\', 'u'}
+			for range 4 {
+				h := p.r.read()
+				if !isHex(h) {
+					return "", p.expectedError("hexadecimal digit", h)
+				}
+				raw = append(raw, byte(h))
+			}
+			sb.WriteString(unescape(raw))
+		default:
+			return "", p.syntaxError(fmt.Errorf("invalid escape character %q", e))
+		}
+	}
+}
+
+// parseHJSONMultiline parses a triple-quoted string. Indentation up to the column
+// of the opening quotes is removed from every line, as are the line
+// breaks right after the opening and right before the closing quotes.
+func (p *parser) parseHJSONMultiline() (*Element, error) {
+	indent := p.r.col
+	p.r.read()
+	p.r.read()
+	p.r.read()
+
+	var sb strings.Builder
+	for {
+		if p.r.isEOF() {
+			return nil, p.expectedError("'''", p.r.read())
+		}
+		if r, _ := p.r.peek(); r == '\'' && p.r.peekByte(1) == '\'' && p.r.peekByte(2) == '\'' {
+			p.r.read()
+			p.r.read()
+			p.r.read()
+			break
+		}
+		if r := p.r.read(); r != '\r' {
+			sb.WriteRune(r)
+		}
+	}
+
+	lines := strings.Split(sb.String(), "\n")
+	if strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) != 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		n := 0
+		for n < len(line) && n < indent && (line[n] == ' ' || line[n] == '\t') {
+			n++
+		}
+		lines[i] = line[n:]
+	}
+
+	return &Element{
+		kind:  StringKind,
+		value: escapeString(strings.Join(lines, "\n")),
+	}, nil
+}
+
+// hjsonSkip skips whitespace and #, // and /* */ comments.
+func (p *parser) hjsonSkip() {
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		switch {
+		case r == '#':
+			for !p.r.isEOF() {
+				if r, _ := p.r.peek(); r == '\n' {
+					break
+				}
+				p.r.read()
+			}
+		case r == '/' && p.skipComment():
+		case isWhitespace(r):
+			p.r.read()
+		default:
+			return
+		}
+	}
+}
+
+func isHJSONPunctuator(r rune) bool {
+	switch r {
+	case '{', '}', '[', ']', ',', ':':
+		return true
+	default:
+		return false
+	}
+}
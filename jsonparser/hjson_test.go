@@ -0,0 +1,130 @@
+package jsonparser
+
+import "testing"
+
+func TestDialectHJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "quoteless keys and strings without commas",
+			input: "{\n  a: 1\n  b: hello world\n}",
+			want:  `{"a":1,"b":"hello world"}`,
+		},
+		{
+			name:  "root braces omitted",
+			input: "a: 1\nb: 2",
+			want:  `{"a":1,"b":2}`,
+		},
+		{
+			name:  "empty document",
+			input: "",
+			want:  `{}`,
+		},
+		{
+			name:  "comments",
+			input: "# hash\n{\n  // line\n  /* block */ a: 1 // trailing\n}",
+			want:  `{"a":1}`,
+		},
+		{
+			name:  "quoteless strings run to the end of the line",
+			input: "{\n  a: text, with commas\n  b: x # not a comment\n  c: 1.5 apples\n}",
+			want:  `{"a":"text, with commas","b":"x # not a comment","c":"1.5 apples"}`,
+		},
+		{
+			name:  "quoteless array elements",
+			input: "[\n  1\n  2\n  three\n]",
+			want:  `[1,2,"three"]`,
+		},
+		{
+			name:  "trailing comma",
+			input: "[1, 2, 3,]",
+			want:  `[1,2,3]`,
+		},
+		{
+			name:  "quoted and punctuated keys",
+			input: `{"quoted key": 1, $weird-key: 2}`,
+			want:  `{"quoted key":1,"$weird-key":2}`,
+		},
+		{
+			name:  "single-quoted string",
+			input: `{a: 'single "q"'}`,
+			want:  `{"a":"single \"q\""}`,
+		},
+		{
+			name:  "multiline string",
+			input: "{\n  md:\n    '''\n    first\n      second\n    third\n    '''\n}",
+			want:  `{"md":"first\n  second\nthird"}`,
+		},
+		{
+			name:  "multiline string on one line",
+			input: "{md: '''one line'''}",
+			want:  `{"md":"one line"}`,
+		},
+		{
+			name:  "member after multiline string",
+			input: "{\n  a:\n  '''\n  x\n  '''\n  b: 1\n}",
+			want:  `{"a":"x","b":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseString(tt.input, WithDialect(DialectHJSON))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := Minify(got); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialectHJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			name:  "closing brace in quoteless string",
+			input: "{a: {b: deep}}",
+			err:   `syntax error in JSON at line 1, column 15: expected: "}", but got: "\x00"`,
+		},
+		{
+			name:  "missing value",
+			input: "{a: }",
+			err:   `syntax error in JSON at line 1, column 5: unexpected token: '}'`,
+		},
+		{
+			name:  "missing colon",
+			input: "{a 1}",
+			err:   `syntax error in JSON at line 1, column 4: expected: ":", but got: "1"`,
+		},
+		{
+			name:  "unterminated multiline string",
+			input: "{a: '''text}",
+			err:   `syntax error in JSON at line 1, column 13: expected: "'''", but got: "\x00"`,
+		},
+		{
+			name:  "mismatched bracket",
+			input: "{a: [1, 2}",
+			err:   `syntax error in JSON at line 1, column 10: unexpected token: '}'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.input, WithDialect(DialectHJSON))
+			if err == nil {
+				t.Fatalf("expected error %q", tt.err)
+			}
+			if err.Error() != tt.err {
+				t.Errorf("got error %q, want %q", err, tt.err)
+			}
+		})
+	}
+}
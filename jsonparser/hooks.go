@@ -0,0 +1,92 @@
+package jsonparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LiteralHook parses literals the parser does not recognize,
+// making it possible to handle supersets of JSON such as
+// undefined or @date(2024-01-01).
+type LiteralHook interface {
+	// ParseLiteral returns the element for the literal text,
+	// or false if the hook does not handle it.
+	ParseLiteral(text string) (*Element, bool, error)
+}
+
+// LiteralFunc adapts a function to the LiteralHook interface.
+type LiteralFunc func(text string) (*Element, bool, error)
+
+func (f LiteralFunc) ParseLiteral(text string) (*Element, bool, error) {
+	return f(text)
+}
+
+// WithLiteralHook registers a hook for values that do not start like
+// an object, array, string or number. Such a literal extends to the next
+// whitespace, ',', ':', ']' or '}' outside of parentheses. The true, false
+// and null literals are handled by the parser, other literals are passed
+// to the hooks in the order they were registered until one handles it.
+func WithLiteralHook(h LiteralHook) Option {
+	return func(c *config) {
+		c.literalHooks = append(c.literalHooks, h)
+	}
+}
+
+func (p *parser) isHookLiteral(r rune) bool {
+	if len(p.cfg.literalHooks) == 0 || p.r.isEOF() {
+		return false
+	}
+	return !isDigit(r) && !strings.ContainsRune("{[\"'+-.,:]}", r) && !isWhitespace(r)
+}
+
+func (p *parser) parseHookLiteral() (*Element, error) {
+	text := p.readLiteral()
+	switch text {
+	case "true", "false":
+		return &Element{kind: BooleanKind, value: text == "true"}, nil
+	case "null":
+		return &Element{kind: NullKind}, nil
+	case "NaN", "Infinity":
+		if p.cfg.nonFiniteNumbers {
+			v, err := p.decodeNumber(text)
+			if err != nil {
+				return nil, err
+			}
+			return &Element{kind: NumberKind, value: v}, nil
+		}
+	}
+
+	for _, h := range p.cfg.literalHooks {
+		el, ok, err := h.ParseLiteral(text)
+		if err != nil {
+			return nil, p.syntaxError(fmt.Errorf("literal %q: %w", text, err))
+		}
+		if ok {
+			// the hook may hand out the same element more than once
+			cp := *el
+			return &cp, nil
+		}
+	}
+	return nil, p.syntaxError(fmt.Errorf("unexpected literal %q", text))
+}
+
+func (p *parser) readLiteral() string {
+	var (
+		sb    strings.Builder
+		depth int
+	)
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		if depth == 0 && (isWhitespace(r) || strings.ContainsRune(",:]}", r)) {
+			break
+		}
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		sb.WriteRune(p.r.read())
+	}
+	return sb.String()
+}
@@ -0,0 +1,138 @@
+package jsonparser
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// ToHTML renders the element as a self-contained HTML page
+// showing the document as a collapsible tree.
+func ToHTML(e *Element, title string) string {
+	var sb strings.Builder
+	_ = WriteHTML(&sb, e, title)
+	return sb.String()
+}
+
+// htmlOpenDepth is the nesting depth up to which
+// objects and arrays are initially expanded.
+const htmlOpenDepth = 2
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; margin: 1em 2em; color: #24292f; }
+button { margin: 0 .5em 1em 0; }
+details > div { margin-left: 1.5em; }
+summary { cursor: pointer; }
+.leaf { margin-left: 1.1em; }
+.key { color: #0550ae; }
+.string { color: #0a3069; }
+.number { color: #953800; }
+.literal { color: #8250df; }
+.count { color: #6e7781; font-style: italic; margin-left: .5em; }
+details[open] > summary > .count { display: none; }
+</style>
+</head>
+<body>
+<button onclick="toggleAll(true)">Expand all</button><button onclick="toggleAll(false)">Collapse all</button>
+`
+
+const htmlFoot = `<script>
+function toggleAll(open) {
+  document.querySelectorAll("details").forEach(function (d) { d.open = open; });
+}
+</script>
+</body>
+</html>
+`
+
+// WriteHTML writes the element to w as an HTML page, like ToHTML.
+func WriteHTML(w io.Writer, e *Element, title string) error {
+	var (
+		bw   = bufio.NewWriter(w)
+		walk func(key string, e *Element, lvl int)
+	)
+
+	label := func(key string) {
+		if key != "" {
+			fmt.Fprintf(bw, `<span class="key">%s</span>: `, html.EscapeString(key))
+		}
+	}
+
+	walk = func(key string, e *Element, lvl int) {
+		e.load()
+		var (
+			opening, closing string
+			n                int
+			unit             string
+		)
+		switch e.kind {
+		case ObjectKind:
+			opening, closing, n, unit = "{", "}", len(e.value.([]Member)), "member"
+		case ArrayKind:
+			opening, closing, n, unit = "[", "]", len(e.value.([]*Element)), "element"
+		}
+
+		if n == 0 {
+			bw.WriteString(`<div class="leaf">`)
+			label(key)
+			bw.WriteString(htmlScalar(e))
+			bw.WriteString("</div>\n")
+			return
+		}
+
+		if lvl < htmlOpenDepth {
+			bw.WriteString("<details open>")
+		} else {
+			bw.WriteString("<details>")
+		}
+		bw.WriteString("<summary>")
+		label(key)
+		bw.WriteString(opening)
+		if n != 1 {
+			unit += "s"
+		}
+		fmt.Fprintf(bw, `<span class="count">%d %s</span></summary>`+"\n<div>\n", n, unit)
+
+		if e.kind == ObjectKind {
+			for _, m := range e.value.([]Member) {
+				walk(`"`+string(m.key)+`"`, m.value, lvl+1)
+			}
+		} else {
+			for i, el := range e.value.([]*Element) {
+				walk(fmt.Sprint(i), el, lvl+1)
+			}
+		}
+		fmt.Fprintf(bw, "</div>\n%s</details>\n", closing)
+	}
+
+	fmt.Fprintf(bw, htmlHead, html.EscapeString(title))
+	walk("", e, 0)
+	bw.WriteString(htmlFoot)
+	return bw.Flush()
+}
+
+// htmlScalar returns the markup of a scalar, an empty object or an empty array.
+func htmlScalar(e *Element) string {
+	switch e.kind {
+	case ObjectKind:
+		return "{}"
+	case ArrayKind:
+		return "[]"
+	case StringKind:
+		return `<span class="string">"` + html.EscapeString(string(e.value.([]byte))) + `"</span>`
+	case NumberKind:
+		return `<span class="number">` + html.EscapeString(numberText(e.value)) + "</span>"
+	case BooleanKind:
+		return fmt.Sprintf(`<span class="literal">%v</span>`, e.value)
+	case NullKind:
+		return `<span class="literal">null</span>`
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,1135 @@
+package jsonparser
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Eval evaluates the jq-like filter expr on the document el and returns
+// its outputs in order, such as the ids and names of the active items
+// for ".items[] | select(.active) | {id, name}". See CompileFilter
+// for the syntax.
+func Eval(el *Element, expr string) ([]*Element, error) {
+	f, err := CompileFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	return f.Eval(el)
+}
+
+// Filter is a compiled jq-like filter, which can be evaluated on
+// many documents and by multiple goroutines.
+type Filter struct {
+	f jqFilter
+}
+
+// CompileFilter parses a jq-like filter. Filters support paths with
+// member names, indices, slices and iteration, the "?" suffix
+// suppressing errors, the recursive descent "..", pipes, commas,
+// array and object construction, literals, arithmetic, comparisons,
+// "and", "or", the alternative "//", and the builtins length, keys,
+// map, select, has, add, type, not and empty.
+func CompileFilter(expr string) (*Filter, error) {
+	p := &jqParser{s: expr}
+	f, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return &Filter{f}, nil
+}
+
+// Eval returns the outputs of the filter for the input el in order.
+// The outputs may share values with el. On errors, Eval returns the
+// outputs produced so far along with the error.
+func (f *Filter) Eval(el *Element) ([]*Element, error) {
+	return f.f.eval(el)
+}
+
+// Match reports whether the filter yields a true value for el, that is
+// any value but false and null, as select in filters.
+func (f *Filter) Match(el *Element) (bool, error) {
+	outputs, err := f.f.eval(el)
+	return slices.ContainsFunc(outputs, jqTruthy), err
+}
+
+// jqFilter is a parsed filter, mapping an input to any number of outputs.
+type jqFilter interface {
+	eval(in *Element) ([]*Element, error)
+}
+
+type jqIdentity struct{}
+
+func (jqIdentity) eval(in *Element) ([]*Element, error) {
+	return []*Element{in}, nil
+}
+
+// jqRecurse is "..", the input and all of its descendants.
+type jqRecurse struct{}
+
+func (jqRecurse) eval(in *Element) ([]*Element, error) {
+	return descendants(in, nil), nil
+}
+
+type jqLiteral struct{ el *Element }
+
+func (f jqLiteral) eval(*Element) ([]*Element, error) {
+	return []*Element{f.el}, nil
+}
+
+// jqPipe feeds every output of left to right.
+type jqPipe struct{ left, right jqFilter }
+
+func (f jqPipe) eval(in *Element) ([]*Element, error) {
+	lefts, err := f.left.eval(in)
+	var out []*Element
+	for _, l := range lefts {
+		rights, err := f.right.eval(l)
+		out = append(out, rights...)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, err
+}
+
+// jqComma yields the outputs of left followed by those of right.
+type jqComma struct{ left, right jqFilter }
+
+func (f jqComma) eval(in *Element) ([]*Element, error) {
+	out, err := f.left.eval(in)
+	if err != nil {
+		return out, err
+	}
+	rights, err := f.right.eval(in)
+	return append(out, rights...), err
+}
+
+// jqTry is the "?" suffix, yielding the outputs before an error.
+type jqTry struct{ filter jqFilter }
+
+func (f jqTry) eval(in *Element) ([]*Element, error) {
+	out, _ := f.filter.eval(in)
+	return out, nil
+}
+
+// jqIndex selects a member or element of the outputs of target.
+// The key is evaluated on the input, as in ".[.i]".
+type jqIndex struct{ target, key jqFilter }
+
+func (f jqIndex) eval(in *Element) ([]*Element, error) {
+	targets, err := f.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := f.key.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Element
+	for _, t := range targets {
+		for _, k := range keys {
+			v, err := jqIndexValue(t, k)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func jqIndexValue(t, k *Element) (*Element, error) {
+	switch {
+	case t.Kind() == NullKind && (k.Kind() == StringKind || k.Kind() == NumberKind):
+		return jqNull(), nil
+	case t.Kind() == ObjectKind && k.Kind() == StringKind:
+		key, _ := k.Str()
+		members, _ := t.Object()
+		for _, m := range members {
+			if m.Key() == key {
+				return m.value, nil
+			}
+		}
+		return jqNull(), nil
+	case t.Kind() == ArrayKind && k.Kind() == NumberKind:
+		elements, _ := t.Array()
+		f, _ := k.Float64()
+		i := int(math.Floor(f))
+		if i < 0 {
+			i += len(elements)
+		}
+		if i < 0 || i >= len(elements) {
+			return jqNull(), nil
+		}
+		return elements[i], nil
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", t.Kind(), jqDescribe(k))
+}
+
+// jqSlice selects a part of arrays and strings. Omitted bounds are nil.
+type jqSlice struct{ target, from, to jqFilter }
+
+func (f jqSlice) eval(in *Element) ([]*Element, error) {
+	targets, err := f.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	bounds := func(b jqFilter) ([]*Element, error) {
+		if b == nil {
+			return []*Element{jqNull()}, nil
+		}
+		return b.eval(in)
+	}
+	froms, err := bounds(f.from)
+	if err != nil {
+		return nil, err
+	}
+	tos, err := bounds(f.to)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Element
+	for _, t := range targets {
+		for _, to := range tos {
+			for _, from := range froms {
+				v, err := jqSliceValue(t, from, to)
+				if err != nil {
+					return out, err
+				}
+				out = append(out, v)
+			}
+		}
+	}
+	return out, nil
+}
+
+func jqSliceValue(t, from, to *Element) (*Element, error) {
+	bound := func(b *Element, n, def int) (int, error) {
+		if b.Kind() == NullKind {
+			return def, nil
+		}
+		f, ok := b.Float64()
+		if !ok {
+			return 0, fmt.Errorf("slice bounds must be numbers, not %s", b.Kind())
+		}
+		i := int(math.Floor(f))
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, 0), n), nil
+	}
+	var n int
+	switch t.Kind() {
+	case NullKind:
+		return jqNull(), nil
+	case ArrayKind:
+		elements, _ := t.Array()
+		n = len(elements)
+	case StringKind:
+		s, _ := t.Str()
+		n = utf8.RuneCountInString(s)
+	default:
+		return nil, fmt.Errorf("cannot slice %s", t.Kind())
+	}
+	start, err := bound(from, n, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(to, n, n)
+	if err != nil {
+		return nil, err
+	}
+	end = max(start, end)
+	if elements, ok := t.Array(); ok {
+		return &Element{kind: ArrayKind, value: slices.Clone(elements[start:end])}, nil
+	}
+	s, _ := t.Str()
+	return jqString(string([]rune(s)[start:end])), nil
+}
+
+// jqIterate is ".[]", the elements of arrays and the member values of objects.
+type jqIterate struct{ target jqFilter }
+
+func (f jqIterate) eval(in *Element) ([]*Element, error) {
+	targets, err := f.target.eval(in)
+	var out []*Element
+	for _, t := range targets {
+		if t.Kind() != ArrayKind && t.Kind() != ObjectKind {
+			return out, fmt.Errorf("cannot iterate over %s", t.Kind())
+		}
+		out = append(out, children(t)...)
+	}
+	return out, err
+}
+
+// jqArray collects the outputs of body, which is nil for "[]".
+type jqArray struct{ body jqFilter }
+
+func (f jqArray) eval(in *Element) ([]*Element, error) {
+	elements := []*Element{}
+	if f.body != nil {
+		out, err := f.body.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, out...)
+	}
+	return []*Element{{kind: ArrayKind, value: elements}}, nil
+}
+
+// jqObject builds an object for every combination of the outputs
+// of its keys and values.
+type jqObject struct{ entries []jqEntry }
+
+type jqEntry struct{ key, value jqFilter }
+
+func (f jqObject) eval(in *Element) ([]*Element, error) {
+	objects := []*Element{{kind: ObjectKind, value: []Member{}}}
+	for _, e := range f.entries {
+		keys, err := e.key.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		values, err := e.value.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		var next []*Element
+		for _, obj := range objects {
+			for _, k := range keys {
+				key, ok := k.Str()
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, not %s", k.Kind())
+				}
+				for _, v := range values {
+					members, _ := obj.Object()
+					o := &Element{kind: ObjectKind, value: slices.Clone(members)}
+					o.SetMember(key, v)
+					next = append(next, o)
+				}
+			}
+		}
+		objects = next
+	}
+	return objects, nil
+}
+
+// jqNeg negates numbers.
+type jqNeg struct{ filter jqFilter }
+
+func (f jqNeg) eval(in *Element) ([]*Element, error) {
+	vals, err := f.filter.eval(in)
+	var out []*Element
+	for _, v := range vals {
+		n, ok := v.Float64()
+		if !ok {
+			return out, fmt.Errorf("cannot negate %s", v.Kind())
+		}
+		neg, err := jqNumber(-n)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, neg)
+	}
+	return out, err
+}
+
+// jqBinary applies an infix operator to the outputs of its operands.
+type jqBinary struct {
+	op          string
+	left, right jqFilter
+}
+
+func (f jqBinary) eval(in *Element) ([]*Element, error) {
+	switch f.op {
+	case "and", "or":
+		lefts, err := f.left.eval(in)
+		var out []*Element
+		for _, l := range lefts {
+			// the right side is only evaluated if it decides the result
+			if jqTruthy(l) == (f.op == "or") {
+				out = append(out, jqBool(f.op == "or"))
+				continue
+			}
+			rights, err := f.right.eval(in)
+			for _, r := range rights {
+				out = append(out, jqBool(jqTruthy(r)))
+			}
+			if err != nil {
+				return out, err
+			}
+		}
+		return out, err
+	case "//":
+		// errors on the left count as false
+		lefts, _ := f.left.eval(in)
+		out := slices.DeleteFunc(lefts, func(l *Element) bool { return !jqTruthy(l) })
+		if len(out) > 0 {
+			return out, nil
+		}
+		return f.right.eval(in)
+	}
+
+	rights, err := f.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	lefts, err := f.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Element
+	for _, r := range rights {
+		for _, l := range lefts {
+			v, err := jqApply(f.op, l, r)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func jqApply(op string, a, b *Element) (*Element, error) {
+	switch op {
+	case "==":
+		return jqBool(jqCompare(a, b) == 0), nil
+	case "!=":
+		return jqBool(jqCompare(a, b) != 0), nil
+	case "<":
+		return jqBool(jqCompare(a, b) < 0), nil
+	case "<=":
+		return jqBool(jqCompare(a, b) <= 0), nil
+	case ">":
+		return jqBool(jqCompare(a, b) > 0), nil
+	case ">=":
+		return jqBool(jqCompare(a, b) >= 0), nil
+	}
+
+	ak, bk := a.Kind(), b.Kind()
+	if ak == NumberKind && bk == NumberKind {
+		x, _ := a.Float64()
+		y, _ := b.Float64()
+		switch op {
+		case "+":
+			return jqNumber(x + y)
+		case "-":
+			return jqNumber(x - y)
+		case "*":
+			return jqNumber(x * y)
+		case "/":
+			if y == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(a), jqDescribe(b))
+			}
+			return jqNumber(x / y)
+		default: // "%"
+			if int64(y) == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", jqDescribe(a), jqDescribe(b))
+			}
+			return jqNumber(float64(int64(x) % int64(y)))
+		}
+	}
+
+	switch {
+	case op == "+" && ak == NullKind:
+		return b, nil
+	case op == "+" && bk == NullKind:
+		return a, nil
+	case op == "+" && ak == StringKind && bk == StringKind:
+		x, _ := a.Str()
+		y, _ := b.Str()
+		return jqString(x + y), nil
+	case op == "+" && ak == ArrayKind && bk == ArrayKind:
+		x, _ := a.Array()
+		y, _ := b.Array()
+		return &Element{kind: ArrayKind, value: slices.Concat(x, y)}, nil
+	case op == "+" && ak == ObjectKind && bk == ObjectKind:
+		members, _ := a.Object()
+		o := &Element{kind: ObjectKind, value: slices.Clone(members)}
+		others, _ := b.Object()
+		for _, m := range others {
+			o.SetMember(m.Key(), m.value)
+		}
+		return o, nil
+	case op == "-" && ak == ArrayKind && bk == ArrayKind:
+		x, _ := a.Array()
+		y, _ := b.Array()
+		elements := slices.DeleteFunc(slices.Clone(x), func(e *Element) bool {
+			return slices.ContainsFunc(y, func(r *Element) bool { return jqCompare(e, r) == 0 })
+		})
+		return &Element{kind: ArrayKind, value: elements}, nil
+	case op == "/" && ak == StringKind && bk == StringKind:
+		x, _ := a.Str()
+		y, _ := b.Str()
+		parts := []*Element{}
+		if x != "" {
+			for _, s := range strings.Split(x, y) {
+				parts = append(parts, jqString(s))
+			}
+		}
+		return &Element{kind: ArrayKind, value: parts}, nil
+	}
+
+	verbs := map[string]string{"+": "added", "-": "subtracted", "*": "multiplied", "/": "divided", "%": "divided"}
+	return nil, fmt.Errorf("%s and %s cannot be %s", jqDescribe(a), jqDescribe(b), verbs[op])
+}
+
+// jqCall calls a builtin.
+type jqCall struct {
+	name string
+	args []jqFilter
+}
+
+// jqBuiltins are the numbers of arguments of the builtins.
+var jqBuiltins = map[string]int{
+	"empty":  0,
+	"not":    0,
+	"length": 0,
+	"keys":   0,
+	"type":   0,
+	"add":    0,
+	"map":    1,
+	"select": 1,
+	"has":    1,
+}
+
+func (f jqCall) eval(in *Element) ([]*Element, error) {
+	switch f.name {
+	case "empty":
+		return nil, nil
+	case "not":
+		return []*Element{jqBool(!jqTruthy(in))}, nil
+	case "type":
+		return []*Element{jqString(in.Kind().String())}, nil
+	case "length":
+		var n float64
+		switch in.Kind() {
+		case NullKind:
+		case NumberKind:
+			f, _ := in.Float64()
+			n = math.Abs(f)
+		case StringKind:
+			s, _ := in.Str()
+			n = float64(utf8.RuneCountInString(s))
+		case ArrayKind, ObjectKind:
+			n = float64(len(children(in)))
+		default:
+			return nil, fmt.Errorf("%s has no length", in.Kind())
+		}
+		v, err := jqNumber(n)
+		return []*Element{v}, err
+	case "keys":
+		keys := []*Element{}
+		switch in.Kind() {
+		case ObjectKind:
+			for _, k := range jqSortedKeys(in) {
+				keys = append(keys, jqString(k))
+			}
+		case ArrayKind:
+			for i := range children(in) {
+				keys = append(keys, intElement(i))
+			}
+		default:
+			return nil, fmt.Errorf("%s has no keys", in.Kind())
+		}
+		return []*Element{{kind: ArrayKind, value: keys}}, nil
+	case "add":
+		if in.Kind() != ArrayKind && in.Kind() != ObjectKind {
+			return nil, fmt.Errorf("cannot iterate over %s", in.Kind())
+		}
+		sum := jqNull()
+		for _, v := range children(in) {
+			var err error
+			if sum, err = jqApply("+", sum, v); err != nil {
+				return nil, err
+			}
+		}
+		return []*Element{sum}, nil
+	case "map":
+		return jqArray{jqPipe{jqIterate{jqIdentity{}}, f.args[0]}}.eval(in)
+	case "select":
+		conds, err := f.args[0].eval(in)
+		var out []*Element
+		for _, c := range conds {
+			if jqTruthy(c) {
+				out = append(out, in)
+			}
+		}
+		return out, err
+	default: // "has"
+		keys, err := f.args[0].eval(in)
+		var out []*Element
+		for _, k := range keys {
+			switch {
+			case in.Kind() == ObjectKind && k.Kind() == StringKind:
+				key, _ := k.Str()
+				_, ok := slices.BinarySearch(jqSortedKeys(in), key)
+				out = append(out, jqBool(ok))
+			case in.Kind() == ArrayKind && k.Kind() == NumberKind:
+				i, _ := k.Float64()
+				out = append(out, jqBool(i >= 0 && i < float64(len(children(in)))))
+			default:
+				return out, fmt.Errorf("cannot check whether %s has %s", in.Kind(), jqDescribe(k))
+			}
+		}
+		return out, err
+	}
+}
+
+// jqTruthy reports whether a value counts as true: all but false and null.
+func jqTruthy(el *Element) bool {
+	b, ok := el.Bool()
+	return !el.IsNull() && (!ok || b)
+}
+
+// jqCompare orders values like jq: null, false, true, numbers, strings,
+// arrays and objects, which are compared by their sorted keys first
+// and then by the values of the keys.
+func jqCompare(a, b *Element) int {
+	rank := func(el *Element) int {
+		if b, ok := el.Bool(); ok && b {
+			return 2
+		}
+		return map[Kind]int{NullKind: 0, BooleanKind: 1, NumberKind: 3, StringKind: 4, ArrayKind: 5, ObjectKind: 6}[el.Kind()]
+	}
+	if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+		return c
+	}
+	switch a.Kind() {
+	case NumberKind:
+		ad, aok := a.Decimal()
+		bd, bok := b.Decimal()
+		if aok && bok {
+			return ad.Cmp(bd)
+		}
+		af, _ := a.Float64()
+		bf, _ := b.Float64()
+		return cmp.Compare(af, bf)
+	case StringKind:
+		as, _ := a.Str()
+		bs, _ := b.Str()
+		return strings.Compare(as, bs)
+	case ArrayKind:
+		ae, _ := a.Array()
+		be, _ := b.Array()
+		return slices.CompareFunc(ae, be, jqCompare)
+	case ObjectKind:
+		ak, bk := jqSortedKeys(a), jqSortedKeys(b)
+		if c := slices.Compare(ak, bk); c != 0 {
+			return c
+		}
+		am, bm := memberMap(a), memberMap(b)
+		for _, k := range ak {
+			if c := jqCompare(am[k], bm[k]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// jqSortedKeys returns the distinct keys of an object in sorted order.
+func jqSortedKeys(el *Element) []string {
+	members, _ := el.Object()
+	keys := make([]string, len(members))
+	for i, m := range members {
+		keys[i] = m.Key()
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// jqDescribe returns a value for messages, quoting strings.
+func jqDescribe(el *Element) string {
+	if s, ok := el.Str(); ok {
+		return strconv.Quote(s)
+	}
+	return el.Kind().String()
+}
+
+func jqNull() *Element {
+	return &Element{kind: NullKind}
+}
+
+func jqBool(b bool) *Element {
+	return &Element{kind: BooleanKind, value: b}
+}
+
+func jqString(s string) *Element {
+	return &Element{kind: StringKind, value: escapeString(s)}
+}
+
+// jqNumber returns a number element for the result of arithmetic,
+// written without an exponent unless it is very large or small.
+func jqNumber(f float64) (*Element, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("number %v out of range", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	return &Element{kind: NumberKind, value: strconv.FormatFloat(f, format, -1, 64)}, nil
+}
+
+// jqParser parses filters by recursive descent, from the lowest
+// precedence of pipes to the highest of paths.
+type jqParser struct {
+	s   string
+	pos int
+}
+
+func (p *jqParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("invalid filter %q at offset %d: %s", p.s, p.pos, fmt.Sprintf(format, a...))
+}
+
+func (p *jqParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// consume skips spaces and s if the input continues with s.
+func (p *jqParser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.s[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// keyword consumes the word w if it is not the start of a longer name.
+func (p *jqParser) keyword(w string) bool {
+	p.skipSpace()
+	start := p.pos
+	if p.ident() == w {
+		return true
+	}
+	p.pos = start
+	return false
+}
+
+// ident parses a name, returning "" if there is none.
+func (p *jqParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || p.pos > start && '0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *jqParser) expect(s string) error {
+	if !p.consume(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+// pipe parses "a | b", which binds to the right.
+func (p *jqParser) pipe() (jqFilter, error) {
+	left, err := p.comma()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume("|") {
+		return left, nil
+	}
+	right, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	return jqPipe{left, right}, nil
+}
+
+func (p *jqParser) comma() (jqFilter, error) {
+	left, err := p.alternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume(",") {
+		right, err := p.alternative()
+		if err != nil {
+			return nil, err
+		}
+		left = jqComma{left, right}
+	}
+	return left, nil
+}
+
+// alternative parses "a // b", which binds to the right.
+func (p *jqParser) alternative() (jqFilter, error) {
+	left, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume("//") {
+		return left, nil
+	}
+	right, err := p.alternative()
+	if err != nil {
+		return nil, err
+	}
+	return jqBinary{"//", left, right}, nil
+}
+
+func (p *jqParser) or() (jqFilter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary{"or", left, right}
+	}
+	return left, nil
+}
+
+func (p *jqParser) and() (jqFilter, error) {
+	left, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary{"and", left, right}
+	}
+	return left, nil
+}
+
+// comparison parses a comparison, which does not chain.
+func (p *jqParser) comparison() (jqFilter, error) {
+	left, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.binary(0)
+			if err != nil {
+				return nil, err
+			}
+			return jqBinary{op, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+// jqArithmetic are the arithmetic operators by increasing precedence.
+var jqArithmetic = [][]string{{"+", "-"}, {"*", "/", "%"}}
+
+// binary parses the left-associative arithmetic operators
+// of jqArithmetic[level] and higher.
+func (p *jqParser) binary(level int) (jqFilter, error) {
+	operand := func() (jqFilter, error) {
+		if level+1 < len(jqArithmetic) {
+			return p.binary(level + 1)
+		}
+		return p.postfix()
+	}
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		op := ""
+		for _, o := range jqArithmetic[level] {
+			// "//" is the alternative operator
+			if strings.HasPrefix(p.s[p.pos:], o) && !strings.HasPrefix(p.s[p.pos:], "//") {
+				op = o
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.pos += len(op)
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = jqBinary{op, left, right}
+	}
+}
+
+// postfix parses a term followed by paths and "?".
+func (p *jqParser) postfix() (jqFilter, error) {
+	f, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		switch {
+		case p.consume("?"):
+			f = jqTry{f}
+		case strings.HasPrefix(p.s[p.pos:], "[") || strings.HasPrefix(p.s[p.pos:], ".["):
+			p.consume(".")
+			if f, err = p.bracket(f); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(p.s[p.pos:], ".") && !strings.HasPrefix(p.s[p.pos:], ".."):
+			p.pos++
+			key, err := p.fieldName()
+			if err != nil {
+				return nil, err
+			}
+			f = jqIndex{f, key}
+		default:
+			return f, nil
+		}
+	}
+}
+
+// fieldName parses the name after a dot, bare or in double quotes.
+func (p *jqParser) fieldName() (jqFilter, error) {
+	if p.pos < len(p.s) && p.s[p.pos] == '"' {
+		return p.stringLiteral()
+	}
+	name := p.ident()
+	if name == "" {
+		return nil, p.errorf("expected a member name")
+	}
+	return jqLiteral{jqString(name)}, nil
+}
+
+// bracket parses an index, a slice or "[]" applied to target.
+func (p *jqParser) bracket(target jqFilter) (jqFilter, error) {
+	p.consume("[")
+	if p.consume("]") {
+		return jqIterate{target}, nil
+	}
+	var from, to jqFilter
+	var err error
+	if !p.consume(":") {
+		if from, err = p.pipe(); err != nil {
+			return nil, err
+		}
+		if p.consume("]") {
+			return jqIndex{target, from}, nil
+		}
+		if !p.consume(":") {
+			return nil, p.errorf("expected \"]\" or \":\"")
+		}
+	}
+	if !p.consume("]") {
+		if to, err = p.pipe(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if from == nil {
+		return nil, p.errorf("expected a slice bound")
+	}
+	return jqSlice{target, from, to}, nil
+}
+
+// term parses a path starting at ".", a literal, a construction,
+// a parenthesized filter, a negation or a builtin.
+func (p *jqParser) term() (jqFilter, error) {
+	p.skipSpace()
+	start := p.pos
+	switch c := p.peek(); {
+	case p.consume(".."):
+		return jqRecurse{}, nil
+	case c == '.':
+		p.pos++
+		switch next := p.peek(); {
+		case next == '[':
+			return p.bracket(jqIdentity{})
+		case next == '"' || next == '_' || 'a' <= next && next <= 'z' || 'A' <= next && next <= 'Z':
+			key, err := p.fieldName()
+			if err != nil {
+				return nil, err
+			}
+			return jqIndex{jqIdentity{}, key}, nil
+		}
+		return jqIdentity{}, nil
+	case c == '"':
+		return p.stringLiteral()
+	case '0' <= c && c <= '9':
+		return p.numberLiteral()
+	case c == '-':
+		p.pos++
+		f, err := p.postfix()
+		if err != nil {
+			return nil, err
+		}
+		return jqNeg{f}, nil
+	case c == '(':
+		p.pos++
+		f, err := p.pipe()
+		if err != nil {
+			return nil, err
+		}
+		return f, p.expect(")")
+	case c == '[':
+		p.pos++
+		if p.consume("]") {
+			return jqArray{}, nil
+		}
+		f, err := p.pipe()
+		if err != nil {
+			return nil, err
+		}
+		return jqArray{f}, p.expect("]")
+	case c == '{':
+		return p.object()
+	}
+
+	name := p.ident()
+	switch name {
+	case "":
+		if p.pos == len(p.s) {
+			return nil, p.errorf("unexpected end of filter")
+		}
+		return nil, p.errorf("unexpected %q", p.s[p.pos:p.pos+1])
+	case "true", "false":
+		return jqLiteral{jqBool(name == "true")}, nil
+	case "null":
+		return jqLiteral{jqNull()}, nil
+	}
+	var args []jqFilter
+	if p.consume("(") {
+		for {
+			arg, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.consume(";") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	if n, ok := jqBuiltins[name]; !ok || n != len(args) {
+		p.pos = start
+		return nil, p.errorf("unknown function %s/%d", name, len(args))
+	}
+	return jqCall{name, args}, nil
+}
+
+func (p *jqParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+// object parses an object construction, whose entries are "key: value"
+// with keys that are names, strings or parenthesized filters, or
+// shorthands like {id} for {id: .id}.
+func (p *jqParser) object() (jqFilter, error) {
+	p.pos++ // {
+	var obj jqObject
+	if p.consume("}") {
+		return obj, nil
+	}
+	for {
+		p.skipSpace()
+		var key jqFilter
+		var err error
+		switch c := p.peek(); {
+		case c == '"':
+			key, err = p.stringLiteral()
+		case c == '(':
+			p.pos++
+			if key, err = p.pipe(); err == nil {
+				err = p.expect(")")
+			}
+		default:
+			name := p.ident()
+			if name == "" {
+				return nil, p.errorf("expected an object key")
+			}
+			key = jqLiteral{jqString(name)}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var value jqFilter
+		if p.consume(":") {
+			// values stop at commas, which separate the entries
+			if value, err = p.alternative(); err != nil {
+				return nil, err
+			}
+		} else if lit, ok := key.(jqLiteral); ok {
+			value = jqIndex{jqIdentity{}, lit}
+		} else {
+			return nil, p.errorf("expected \":\"")
+		}
+		obj.entries = append(obj.entries, jqEntry{key, value})
+
+		if p.consume("}") {
+			return obj, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// stringLiteral parses a string in double quotes with JSON escapes.
+func (p *jqParser) stringLiteral() (jqFilter, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.s) && p.s[p.pos] != '"' {
+		if p.s[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		p.pos = start
+		return nil, p.errorf("unterminated string")
+	}
+	p.pos++
+	el, err := ParseString(p.s[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid string")
+	}
+	return jqLiteral{el}, nil
+}
+
+// numberLiteral parses a number, which is kept as written.
+func (p *jqParser) numberLiteral() (jqFilter, error) {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("0123456789.eE", p.s[p.pos]) >= 0 {
+		// signs only follow exponents
+		if c := p.s[p.pos]; (c == 'e' || c == 'E') && p.pos+1 < len(p.s) && strings.IndexByte("+-", p.s[p.pos+1]) >= 0 {
+			p.pos++
+		}
+		p.pos++
+	}
+	el, err := ParseString(p.s[start:p.pos])
+	if err != nil || el.Kind() != NumberKind {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return jqLiteral{el}, nil
+}
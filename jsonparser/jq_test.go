@@ -0,0 +1,172 @@
+package jsonparser
+
+import (
+	"slices"
+	"testing"
+)
+
+const inventory = `{
+  "items": [
+    {"id": 1, "name": "a", "active": true, "tags": ["x", "y"], "price": 2.5},
+    {"id": 2, "name": "b", "active": false, "tags": []},
+    {"id": 3, "name": "c", "active": true, "price": null}
+  ],
+  "count": 3
+}`
+
+func TestEval(t *testing.T) {
+	doc, err := ParseString(inventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: `.items[] | select(.active) | {id, name}`, want: []string{`{"id":1,"name":"a"}`, `{"id":3,"name":"c"}`}},
+		{filter: `.`, want: []string{Minify(doc)}},
+		{filter: `.items[0].name`, want: []string{`"a"`}},
+		{filter: `.items[0]["name"]`, want: []string{`"a"`}},
+		{filter: `.items[-1].id`, want: []string{`3`}},
+		{filter: `.missing`, want: []string{`null`}},
+		{filter: `[.items[].id][1:2]`, want: []string{`[2]`}},
+		{filter: `.items[1:] | length`, want: []string{`2`}},
+		{filter: `.items[]?.id`, want: []string{`1`, `2`, `3`}},
+		{filter: `.count?`, want: []string{`3`}},
+		{filter: `.count[]?`, want: []string{}},
+		{filter: `[..] | length`, want: []string{`21`}},
+		{filter: `1, 2`, want: []string{`1`, `2`}},
+		{filter: `empty`, want: []string{}},
+		{filter: `{a: 1, "b": [1, 2]} | .b[1]`, want: []string{`2`}},
+		{filter: `{(.items[0].name): .count}`, want: []string{`{"a":3}`}},
+		{filter: `[.items[].name]`, want: []string{`["a","b","c"]`}},
+		{filter: `.items[0].price * 2 + 1`, want: []string{`6`}},
+		{filter: `.items[0].name + "z"`, want: []string{`"az"`}},
+		{filter: `-.count`, want: []string{`-3`}},
+		{filter: `.count > 2 and .count < 5`, want: []string{`true`}},
+		{filter: `.count == 3 or .missing`, want: []string{`true`}},
+		{filter: `.items[] | .price // 0`, want: []string{`2.5`, `0`, `0`}},
+		{filter: `.items | length`, want: []string{`3`}},
+		{filter: `.items[].tags | length`, want: []string{`2`, `0`, `0`}},
+		{filter: `"abc" | length`, want: []string{`3`}},
+		{filter: `keys`, want: []string{`["count","items"]`}},
+		{filter: `.items[0] | keys`, want: []string{`["active","id","name","price","tags"]`}},
+		{filter: `.items | map(.id)`, want: []string{`[1,2,3]`}},
+		{filter: `[.items[] | .id] | map(. * 10)`, want: []string{`[10,20,30]`}},
+		{filter: `.items[0] | has("tags")`, want: []string{`true`}},
+		{filter: `.items | map(select(has("price"))) | length`, want: []string{`2`}},
+		{filter: `[.items[].id] | add`, want: []string{`6`}},
+		{filter: `.items[1].tags | add`, want: []string{`null`}},
+		{filter: `.items[0] | type`, want: []string{`"object"`}},
+		{filter: `.items[0].active | not`, want: []string{`false`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := Eval(doc, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := minifyAll(got); !slices.Equal(s, tt.want) {
+				t.Errorf("got %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	doc, err := ParseString(inventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter string
+		err    string
+	}{
+		{filter: `.count[]`, err: `cannot iterate over number`},
+		{filter: `.count[0]`, err: `cannot index number with number`},
+		{filter: `.items.name`, err: `cannot index array with "name"`},
+		{filter: `.items | keys | .[0] | keys`, err: `number has no keys`},
+		{filter: `1 / 0`, err: `number and number cannot be divided because the divisor is zero`},
+		{filter: `.items[0].tags - 1`, err: `array and number cannot be subtracted`},
+		{filter: `"a" * {}`, err: `"a" and object cannot be multiplied`},
+		{filter: `{(.count): 1}`, err: `object keys must be strings, not number`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := Eval(doc, tt.filter)
+			if err == nil {
+				t.Fatalf("expected error %q", tt.err)
+			}
+			if err.Error() != tt.err {
+				t.Errorf("got error %q, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		err    string
+	}{
+		{filter: ``, err: `invalid filter "" at offset 0: unexpected end of filter`},
+		{filter: `)`, err: `invalid filter ")" at offset 0: unexpected ")"`},
+		{filter: `.a |`, err: `invalid filter ".a |" at offset 4: unexpected end of filter`},
+		{filter: `.[`, err: `invalid filter ".[" at offset 2: unexpected end of filter`},
+		{filter: `.[1:`, err: `invalid filter ".[1:" at offset 4: unexpected end of filter`},
+		{filter: `.a["b"`, err: `invalid filter ".a[\"b\"" at offset 6: expected "]" or ":"`},
+		{filter: `. .`, err: `invalid filter ". ." at offset 3: expected a member name`},
+		{filter: `foo`, err: `invalid filter "foo" at offset 0: unknown function foo/0`},
+		{filter: `map`, err: `invalid filter "map" at offset 0: unknown function map/0`},
+		{filter: `map(.a`, err: `invalid filter "map(.a" at offset 6: expected ")"`},
+		{filter: `{a:}`, err: `invalid filter "{a:}" at offset 3: unexpected "}"`},
+		{filter: `{1: 2}`, err: `invalid filter "{1: 2}" at offset 1: expected an object key`},
+		{filter: `"abc`, err: `invalid filter "\"abc" at offset 0: unterminated string`},
+		{filter: `1.2.3`, err: `invalid filter "1.2.3" at offset 0: invalid number`},
+		{filter: `.count as $x`, err: `invalid filter ".count as $x" at offset 7: unexpected "as $x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := CompileFilter(tt.filter)
+			if err == nil {
+				t.Fatalf("expected error %q", tt.err)
+			}
+			if err.Error() != tt.err {
+				t.Errorf("got error %q, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	f, err := CompileFilter(`.age > 30`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: `{"age": 31}`, want: true},
+		{input: `{"age": 30}`, want: false},
+		{input: `{}`, want: false},
+	}
+	for _, tt := range tests {
+		doc, err := ParseString(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := f.Match(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,936 @@
+package jsonparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Query evaluates the JSONPath (RFC 9535) expression expr on the document
+// el and returns the selected nodes in order, such as the titles of the
+// cheap books for "$.store.book[?@.price < 10].title". Expressions support
+// name, index, slice, wildcard and filter selectors, the descendant
+// segment "..", and the functions length, count, match, search and value.
+func Query(el *Element, expr string) ([]*Element, error) {
+	q, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	ev := &pathEval{root: el, regexps: make(map[string]*regexp.Regexp)}
+	return q.nodes(ev, el), nil
+}
+
+// pathQuery is a parsed JSONPath query.
+type pathQuery struct {
+	// relative queries start at the current node "@" of a filter,
+	// others at the root "$".
+	relative bool
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	// descendant segments apply the selectors to the nodes
+	// and all of their descendants.
+	descendant bool
+	selectors  []pathSelector
+}
+
+type selectorKind uint8
+
+const (
+	selectName selectorKind = iota
+	selectWildcard
+	selectIndex
+	selectSlice
+	selectFilter
+)
+
+type pathSelector struct {
+	kind  selectorKind
+	name  string
+	index int
+	// start, end and step of slices, nil if omitted
+	start, end, step *int
+	filter           filterExpr
+}
+
+// singular reports whether the query selects at most one node.
+func (q *pathQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		if k := seg.selectors[0].kind; k != selectName && k != selectIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// pathEval holds the state of evaluating a query.
+type pathEval struct {
+	root *Element
+	// regexps caches the expressions of match and search.
+	regexps map[string]*regexp.Regexp
+}
+
+// nodes returns the nodes selected by the query from cur,
+// the current node of a filter.
+func (q *pathQuery) nodes(ev *pathEval, cur *Element) []*Element {
+	nodes := []*Element{ev.root}
+	if q.relative {
+		nodes[0] = cur
+	}
+	for _, seg := range q.segments {
+		var next []*Element
+		for _, n := range nodes {
+			if !seg.descendant {
+				next = seg.apply(ev, n, next)
+				continue
+			}
+			for _, d := range descendants(n, nil) {
+				next = seg.apply(ev, d, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// apply appends the nodes selected from n by the selectors to out.
+func (seg *pathSegment) apply(ev *pathEval, n *Element, out []*Element) []*Element {
+	for _, sel := range seg.selectors {
+		out = sel.apply(ev, n, out)
+	}
+	return out
+}
+
+func (sel *pathSelector) apply(ev *pathEval, n *Element, out []*Element) []*Element {
+	switch sel.kind {
+	case selectName:
+		members, _ := n.Object()
+		for _, m := range members {
+			if m.Key() == sel.name {
+				return append(out, m.value)
+			}
+		}
+	case selectWildcard:
+		out = append(out, children(n)...)
+	case selectIndex:
+		elements, _ := n.Array()
+		i := sel.index
+		if i < 0 {
+			i += len(elements)
+		}
+		if i >= 0 && i < len(elements) {
+			out = append(out, elements[i])
+		}
+	case selectSlice:
+		elements, ok := n.Array()
+		if !ok {
+			break
+		}
+		for _, i := range sliceIndices(len(elements), sel.start, sel.end, sel.step) {
+			out = append(out, elements[i])
+		}
+	case selectFilter:
+		for _, c := range children(n) {
+			if sel.filter.test(ev, c) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// sliceIndices returns the indices selected by a slice
+// from an array of length n, as defined by RFC 9535.
+func sliceIndices(n int, start, end, step *int) []int {
+	s := 1
+	if step != nil {
+		s = *step
+	}
+	if s == 0 {
+		return nil
+	}
+	normalize := func(i *int, def int) int {
+		if i == nil {
+			return def
+		}
+		if *i < 0 {
+			return n + *i
+		}
+		return *i
+	}
+
+	var indices []int
+	if s > 0 {
+		lower := min(max(normalize(start, 0), 0), n)
+		upper := min(max(normalize(end, n), 0), n)
+		for i := lower; i < upper; i += s {
+			indices = append(indices, i)
+		}
+	} else {
+		upper := min(max(normalize(start, n-1), -1), n-1)
+		lower := min(max(normalize(end, -n-1), -1), n-1)
+		for i := upper; lower < i; i += s {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// children returns the member values of an object
+// or the elements of an array.
+func children(n *Element) []*Element {
+	if elements, ok := n.Array(); ok {
+		return elements
+	}
+	members, _ := n.Object()
+	values := make([]*Element, len(members))
+	for i, m := range members {
+		values[i] = m.value
+	}
+	return values
+}
+
+// descendants appends n and its descendants in document order to out.
+func descendants(n *Element, out []*Element) []*Element {
+	out = append(out, n)
+	for _, c := range children(n) {
+		out = descendants(c, out)
+	}
+	return out
+}
+
+// filterExpr is a logical expression of a filter selector.
+type filterExpr interface {
+	test(ev *pathEval, cur *Element) bool
+}
+
+// valueExpr is an expression yielding a value in a filter,
+// or nil for Nothing.
+type valueExpr interface {
+	value(ev *pathEval, cur *Element) *Element
+}
+
+type orExpr []filterExpr
+
+func (e orExpr) test(ev *pathEval, cur *Element) bool {
+	for _, t := range e {
+		if t.test(ev, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr []filterExpr
+
+func (e andExpr) test(ev *pathEval, cur *Element) bool {
+	for _, t := range e {
+		if !t.test(ev, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+type notExpr struct{ expr filterExpr }
+
+func (e notExpr) test(ev *pathEval, cur *Element) bool {
+	return !e.expr.test(ev, cur)
+}
+
+// existsExpr tests whether a query selects any node.
+type existsExpr struct{ query *pathQuery }
+
+func (e existsExpr) test(ev *pathEval, cur *Element) bool {
+	return len(e.query.nodes(ev, cur)) > 0
+}
+
+type comparison struct {
+	op          string
+	left, right valueExpr
+}
+
+func (e comparison) test(ev *pathEval, cur *Element) bool {
+	a, b := e.left.value(ev, cur), e.right.value(ev, cur)
+	switch e.op {
+	case "==":
+		return pathEqual(a, b)
+	case "!=":
+		return !pathEqual(a, b)
+	case "<":
+		return pathLess(a, b)
+	case "<=":
+		return pathLess(a, b) || pathEqual(a, b)
+	case ">":
+		return pathLess(b, a)
+	default: // ">="
+		return pathLess(b, a) || pathEqual(a, b)
+	}
+}
+
+func pathEqual(a, b *Element) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return Equal(a, b)
+}
+
+func pathLess(a, b *Element) bool {
+	if a == nil || b == nil || a.Kind() != b.Kind() {
+		return false
+	}
+	switch a.Kind() {
+	case NumberKind:
+		ad, aok := a.Decimal()
+		bd, bok := b.Decimal()
+		return aok && bok && ad.Cmp(bd) < 0
+	case StringKind:
+		as, _ := a.Str()
+		bs, _ := b.Str()
+		return as < bs
+	default:
+		return false
+	}
+}
+
+type literalExpr struct{ el *Element }
+
+func (e literalExpr) value(*pathEval, *Element) *Element { return e.el }
+
+// singularQuery yields the node selected by a singular query.
+type singularQuery struct{ query *pathQuery }
+
+func (e singularQuery) value(ev *pathEval, cur *Element) *Element {
+	nodes := e.query.nodes(ev, cur)
+	if len(nodes) != 1 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// funcCall calls a function extension. Its arguments are valueExpr
+// for parameters of ValueType and *pathQuery for NodesType.
+type funcCall struct {
+	name string
+	args []any
+}
+
+func (f *funcCall) value(ev *pathEval, cur *Element) *Element {
+	switch f.name {
+	case "length":
+		v := f.args[0].(valueExpr).value(ev, cur)
+		if v == nil {
+			return nil
+		}
+		switch v.Kind() {
+		case StringKind:
+			s, _ := v.Str()
+			return intElement(utf8.RuneCountInString(s))
+		case ArrayKind, ObjectKind:
+			return intElement(len(children(v)))
+		}
+		return nil
+	case "count":
+		return intElement(len(f.args[0].(*pathQuery).nodes(ev, cur)))
+	default: // "value"
+		nodes := f.args[0].(*pathQuery).nodes(ev, cur)
+		if len(nodes) != 1 {
+			return nil
+		}
+		return nodes[0]
+	}
+}
+
+// test evaluates match and search.
+func (f *funcCall) test(ev *pathEval, cur *Element) bool {
+	s, ok1 := strValue(f.args[0].(valueExpr).value(ev, cur))
+	pattern, ok2 := strValue(f.args[1].(valueExpr).value(ev, cur))
+	if !ok1 || !ok2 {
+		return false
+	}
+	if f.name == "match" {
+		pattern = `\A(?:` + pattern + `)\z`
+	}
+	re, ok := ev.regexps[pattern]
+	if !ok {
+		// invalid expressions match nothing
+		re, _ = regexp.Compile(pattern)
+		ev.regexps[pattern] = re
+	}
+	return re != nil && re.MatchString(s)
+}
+
+func strValue(el *Element) (string, bool) {
+	if el == nil {
+		return "", false
+	}
+	return el.Str()
+}
+
+func intElement(n int) *Element {
+	return &Element{kind: NumberKind, value: strconv.Itoa(n)}
+}
+
+// functions are the result types of the function extensions,
+// with the types of their parameters.
+var functions = map[string]struct {
+	logical bool
+	// params holds true for NodesType and false for ValueType.
+	params []bool
+}{
+	"length": {params: []bool{false}},
+	"count":  {params: []bool{true}},
+	"match":  {logical: true, params: []bool{false, false}},
+	"search": {logical: true, params: []bool{false, false}},
+	"value":  {params: []bool{true}},
+}
+
+// pathParser parses JSONPath expressions.
+type pathParser struct {
+	s   string
+	pos int
+}
+
+func parseJSONPath(expr string) (*pathQuery, error) {
+	p := &pathParser{s: expr}
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with $")
+	}
+	q, err := p.segments(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return q, nil
+}
+
+func (p *pathParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("invalid JSONPath %q at offset %d: %s", p.s, p.pos, fmt.Sprintf(format, a...))
+}
+
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *pathParser) consume(s string) bool {
+	if strings.HasPrefix(p.s[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *pathParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// segments parses the segments following "$" or "@".
+func (p *pathParser) segments(relative bool) (*pathQuery, error) {
+	q := &pathQuery{relative: relative}
+	for {
+		start := p.pos
+		p.skipSpace()
+		var seg pathSegment
+		switch {
+		case p.consume(".."):
+			seg.descendant = true
+			if p.peek() == '[' {
+				sels, err := p.bracketed()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = sels
+				break
+			}
+			fallthrough
+		case p.consume("."):
+			if p.consume("*") {
+				seg.selectors = []pathSelector{{kind: selectWildcard}}
+				break
+			}
+			name := p.memberName()
+			if name == "" {
+				return nil, p.errorf("expected a member name or *")
+			}
+			seg.selectors = []pathSelector{{kind: selectName, name: name}}
+		case p.peek() == '[':
+			sels, err := p.bracketed()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = sels
+		default:
+			// the blank space belongs to the enclosing expression
+			p.pos = start
+			return q, nil
+		}
+		q.segments = append(q.segments, seg)
+	}
+}
+
+// memberName parses the name of a member in dot notation.
+func (p *pathParser) memberName() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if !(r == '_' || r >= 0x80 || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' ||
+			p.pos > start && '0' <= r && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	return p.s[start:p.pos]
+}
+
+// bracketed parses the comma-separated selectors in brackets.
+func (p *pathParser) bracketed() ([]pathSelector, error) {
+	p.pos++ // [
+	var sels []pathSelector
+	for {
+		p.skipSpace()
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+		p.skipSpace()
+		if p.consume("]") {
+			return sels, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *pathParser) selector() (pathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.stringLiteral()
+		return pathSelector{kind: selectName, name: s}, err
+	case c == '*':
+		p.pos++
+		return pathSelector{kind: selectWildcard}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		f, err := p.logicalOr()
+		return pathSelector{kind: selectFilter, filter: f}, err
+	case c == '-' || c == ':' || '0' <= c && c <= '9':
+		return p.indexOrSlice()
+	default:
+		return pathSelector{}, p.errorf("invalid selector")
+	}
+}
+
+func (p *pathParser) indexOrSlice() (pathSelector, error) {
+	var bounds [3]*int
+	for i := range bounds {
+		if i > 0 {
+			p.skipSpace()
+			if !p.consume(":") {
+				if i == 1 {
+					// an index
+					return pathSelector{kind: selectIndex, index: *bounds[0]}, nil
+				}
+				break
+			}
+			p.skipSpace()
+		}
+		if c := p.peek(); c == '-' || '0' <= c && c <= '9' {
+			n, err := p.integer()
+			if err != nil {
+				return pathSelector{}, err
+			}
+			bounds[i] = &n
+		} else if i == 0 {
+			// a slice without start
+			continue
+		}
+	}
+	return pathSelector{kind: selectSlice, start: bounds[0], end: bounds[1], step: bounds[2]}, nil
+}
+
+// maxPathInt bounds the integers of expressions to the exact range of I-JSON.
+const maxPathInt = 1<<53 - 1
+
+func (p *pathParser) integer() (int, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for p.pos < len(p.s) && '0' <= p.s[p.pos] && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	text := p.s[start:p.pos]
+	if p.pos == digits || p.s[digits] == '0' && (p.pos-digits > 1 || digits > start) {
+		p.pos = start
+		return 0, p.errorf("invalid integer %q", text)
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n > maxPathInt || n < -maxPathInt {
+		p.pos = start
+		return 0, p.errorf("integer %s out of range", text)
+	}
+	return n, nil
+}
+
+// stringLiteral parses a string in single or double quotes.
+func (p *pathParser) stringLiteral() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c != '\\':
+			sb.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		p.pos++
+		esc := p.peek()
+		p.pos++
+		switch esc {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '/', '\\':
+			sb.WriteByte(esc)
+		case 'u':
+			r, err := p.unicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteRune(r)
+		default:
+			if esc != quote {
+				p.pos--
+				return "", p.errorf("invalid escape")
+			}
+			sb.WriteByte(esc)
+		}
+	}
+}
+
+// unicodeEscape parses the hex digits of a \u escape,
+// combining surrogate pairs.
+func (p *pathParser) unicodeEscape() (rune, error) {
+	hex := func() (rune, error) {
+		if p.pos+4 > len(p.s) {
+			return 0, p.errorf("invalid unicode escape")
+		}
+		n, err := strconv.ParseUint(p.s[p.pos:p.pos+4], 16, 16)
+		if err != nil {
+			return 0, p.errorf("invalid unicode escape")
+		}
+		p.pos += 4
+		return rune(n), nil
+	}
+	r, err := hex()
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+	if !p.consume(`\u`) {
+		return 0, p.errorf("unpaired surrogate")
+	}
+	low, err := hex()
+	if err != nil {
+		return 0, err
+	}
+	if r = utf16.DecodeRune(r, low); r == utf8.RuneError {
+		return 0, p.errorf("invalid surrogate pair")
+	}
+	return r, nil
+}
+
+func (p *pathParser) logicalOr() (filterExpr, error) {
+	var terms orExpr
+	for {
+		t, err := p.logicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+		p.skipSpace()
+		if !p.consume("||") {
+			break
+		}
+		p.skipSpace()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *pathParser) logicalAnd() (filterExpr, error) {
+	var terms andExpr
+	for {
+		t, err := p.basicExpr()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, t)
+		p.skipSpace()
+		if !p.consume("&&") {
+			break
+		}
+		p.skipSpace()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *pathParser) basicExpr() (filterExpr, error) {
+	if p.consume("!") {
+		p.skipSpace()
+		if p.peek() == '(' {
+			e, err := p.parenExpr()
+			return notExpr{e}, err
+		}
+		start := p.pos
+		operand, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		e, err := p.testExpr(operand, start)
+		return notExpr{e}, err
+	}
+	if p.peek() == '(' {
+		return p.parenExpr()
+	}
+
+	start := p.pos
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	save := p.pos
+	p.skipSpace()
+	op := p.comparisonOp()
+	if op == "" {
+		p.pos = save
+		return p.testExpr(left, start)
+	}
+	p.skipSpace()
+	rightStart := p.pos
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	l, err := p.comparable(left, start)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.comparable(right, rightStart)
+	if err != nil {
+		return nil, err
+	}
+	return comparison{op: op, left: l, right: r}, nil
+}
+
+func (p *pathParser) parenExpr() (filterExpr, error) {
+	p.pos++ // (
+	p.skipSpace()
+	e, err := p.logicalOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.consume(")") {
+		return nil, p.errorf("expected )")
+	}
+	return e, nil
+}
+
+func (p *pathParser) comparisonOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// operand parses a query, a function call or a literal, returning
+// *pathQuery, *funcCall or literalExpr.
+func (p *pathParser) operand() (any, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		return p.segments(c == '@')
+	case c == '\'' || c == '"':
+		s, err := p.stringLiteral()
+		return literalExpr{&Element{kind: StringKind, value: escapeString(s)}}, err
+	case c == '-' || '0' <= c && c <= '9':
+		return p.numberLiteral()
+	case 'a' <= c && c <= 'z':
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] == '_' || 'a' <= p.s[p.pos] && p.s[p.pos] <= 'z' ||
+			'0' <= p.s[p.pos] && p.s[p.pos] <= '9') {
+			p.pos++
+		}
+		name := p.s[start:p.pos]
+		if p.peek() == '(' {
+			return p.funcCall(name, start)
+		}
+		switch name {
+		case "true", "false":
+			return literalExpr{&Element{kind: BooleanKind, value: name == "true"}}, nil
+		case "null":
+			return literalExpr{&Element{kind: NullKind}}, nil
+		}
+		p.pos = start
+		return nil, p.errorf("unknown name %q", name)
+	default:
+		return nil, p.errorf("expected a query, a function or a literal")
+	}
+}
+
+func (p *pathParser) numberLiteral() (any, error) {
+	start := p.pos
+	digits := func() int {
+		n := p.pos
+		for p.pos < len(p.s) && '0' <= p.s[p.pos] && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		return p.pos - n
+	}
+	p.consume("-")
+	intStart := p.pos
+	if n := digits(); n == 0 || n > 1 && p.s[intStart] == '0' {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	if p.consume(".") && digits() == 0 {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	if p.consume("e") || p.consume("E") {
+		if !p.consume("+") {
+			p.consume("-")
+		}
+		if digits() == 0 {
+			p.pos = start
+			return nil, p.errorf("invalid number")
+		}
+	}
+	return literalExpr{&Element{kind: NumberKind, value: p.s[start:p.pos]}}, nil
+}
+
+func (p *pathParser) funcCall(name string, start int) (*funcCall, error) {
+	fn, ok := functions[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %q", name)
+	}
+	p.pos++ // (
+	call := &funcCall{name: name}
+	for i := 0; ; i++ {
+		p.skipSpace()
+		if i == 0 && p.consume(")") {
+			break
+		}
+		argStart := p.pos
+		arg, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if i < len(fn.params) {
+			if fn.params[i] {
+				q, ok := arg.(*pathQuery)
+				if !ok {
+					p.pos = argStart
+					return nil, p.errorf("argument %d of %s must be a query", i+1, name)
+				}
+				call.args = append(call.args, q)
+			} else {
+				v, err := p.comparable(arg, argStart)
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, v)
+			}
+		}
+		p.skipSpace()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+	if len(call.args) != len(fn.params) {
+		p.pos = start
+		return nil, p.errorf("%s takes %d argument(s)", name, len(fn.params))
+	}
+	return call, nil
+}
+
+// comparable checks that the operand starting at start yields a value.
+func (p *pathParser) comparable(operand any, start int) (valueExpr, error) {
+	switch o := operand.(type) {
+	case literalExpr:
+		return o, nil
+	case *pathQuery:
+		if !o.singular() {
+			p.pos = start
+			return nil, p.errorf("query in comparison must select a single node")
+		}
+		return singularQuery{o}, nil
+	case *funcCall:
+		if functions[o.name].logical {
+			p.pos = start
+			return nil, p.errorf("%s does not return a value", o.name)
+		}
+		return o, nil
+	}
+	panic("unreachable")
+}
+
+// testExpr checks that the operand starting at start is a test:
+// a query or a function returning a logical value.
+func (p *pathParser) testExpr(operand any, start int) (filterExpr, error) {
+	switch o := operand.(type) {
+	case *pathQuery:
+		return existsExpr{o}, nil
+	case *funcCall:
+		if functions[o.name].logical {
+			return o, nil
+		}
+	}
+	p.pos = start
+	return nil, p.errorf("expected a comparison, a query or a logical function")
+}
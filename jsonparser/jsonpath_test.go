@@ -0,0 +1,142 @@
+package jsonparser
+
+import (
+	"slices"
+	"testing"
+)
+
+// bookstore is the example document of RFC 9535.
+const bookstore = `{
+  "store": {
+    "book": [
+      {"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+      {"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+      {"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+      {"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+    ],
+    "bicycle": {"color": "red", "price": 399}
+  }
+}`
+
+// minifyAll returns the minified text of every element.
+func minifyAll(elements []*Element) []string {
+	out := make([]string, len(elements))
+	for i, el := range elements {
+		out[i] = Minify(el)
+	}
+	return out
+}
+
+func TestQuery(t *testing.T) {
+	doc, err := ParseString(bookstore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	titles := []string{`"Sayings of the Century"`, `"Sword of Honour"`, `"Moby Dick"`, `"The Lord of the Rings"`}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$`, want: []string{Minify(doc)}},
+		{query: `$.store.book[*].author`, want: []string{`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`}},
+		{query: `$..author`, want: []string{`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`}},
+		{query: `$.store..price`, want: []string{`8.95`, `12.99`, `8.99`, `22.99`, `399`}},
+		{query: `$['store']["bicycle"].color`, want: []string{`"red"`}},
+		{query: `$.store.*.color`, want: []string{`"red"`}},
+		{query: `$..book[2].title`, want: titles[2:3]},
+		{query: `$..book[-1].title`, want: titles[3:]},
+		{query: `$..book[0,1].title`, want: titles[:2]},
+		{query: `$..book[:2].title`, want: titles[:2]},
+		{query: `$..book[1:3].title`, want: titles[1:3]},
+		{query: `$..book[-2:].title`, want: titles[2:]},
+		{query: `$..book[::2].title`, want: []string{titles[0], titles[2]}},
+		{query: `$..book[::-1].title`, want: []string{titles[3], titles[2], titles[1], titles[0]}},
+		{query: `$..book[3:0:-2].title`, want: []string{titles[3], titles[1]}},
+		{query: `$..book[1:2:0].title`, want: []string{}},
+		{query: `$..book[?@.isbn].title`, want: titles[2:]},
+		{query: `$..book[?!@.isbn].title`, want: titles[:2]},
+		{query: `$..book[?@.price < 10].title`, want: []string{titles[0], titles[2]}},
+		{query: `$..book[?(@.price<10)].title`, want: []string{titles[0], titles[2]}},
+		{query: `$..book[?@.price > 10 && @.category == 'fiction'].title`, want: []string{titles[1], titles[3]}},
+		{query: `$..book[?@.price > 20 || @.category == 'reference'].title`, want: []string{titles[0], titles[3]}},
+		{query: `$..book[?@.price == $.store.book[0].price].title`, want: titles[:1]},
+		{query: `$..book[?@.author == "Nigel Rees"].price`, want: []string{`8.95`}},
+		{query: `$..book[?length(@.title) > 15].title`, want: []string{titles[0], titles[3]}},
+		{query: `$..book[?match(@.author, "J.*")].author`, want: []string{`"J. R. R. Tolkien"`}},
+		{query: `$..book[?match(@.author, "Mel")].author`, want: []string{}},
+		{query: `$..book[?search(@.author, "Mel")].author`, want: []string{`"Herman Melville"`}},
+		{query: `$.store[?count(@.*) == 2].color`, want: []string{`"red"`}},
+		{query: `$..book[?value(@.price) == 8.99].title`, want: titles[2:3]},
+		{query: `$.nothing`, want: []string{}},
+		{query: `$.store.book[10]`, want: []string{}},
+		{query: `$.store.book.title`, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := Query(doc, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := minifyAll(got); !slices.Equal(s, tt.want) {
+				t.Errorf("got %v, want %v", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryDescendants(t *testing.T) {
+	doc, err := ParseString(bookstore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Query(doc, `$..*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// store, book, bicycle, the 4 books, their 18 members
+	// and the 2 members of bicycle
+	if len(got) != 27 {
+		t.Errorf("got %d nodes, want 27", len(got))
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	doc, err := ParseString(bookstore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{query: ``, err: `invalid JSONPath "" at offset 0: query must start with $`},
+		{query: `store`, err: `invalid JSONPath "store" at offset 0: query must start with $`},
+		{query: `$.`, err: `invalid JSONPath "$." at offset 2: expected a member name or *`},
+		{query: `$..`, err: `invalid JSONPath "$.." at offset 3: expected a member name or *`},
+		{query: `$[`, err: `invalid JSONPath "$[" at offset 2: invalid selector`},
+		{query: `$[01]`, err: `invalid JSONPath "$[01]" at offset 2: invalid integer "01"`},
+		{query: `$.a[1:2:0`, err: `invalid JSONPath "$.a[1:2:0" at offset 9: expected , or ]`},
+		{query: `$['abc`, err: `invalid JSONPath "$['abc" at offset 6: unterminated string`},
+		{query: `$[?@.a ==]`, err: `invalid JSONPath "$[?@.a ==]" at offset 9: expected a query, a function or a literal`},
+		{query: `$[?@.a == 1 &&]`, err: `invalid JSONPath "$[?@.a == 1 &&]" at offset 14: expected a query, a function or a literal`},
+		{query: `$[?length(@)]`, err: `invalid JSONPath "$[?length(@)]" at offset 3: expected a comparison, a query or a logical function`},
+		{query: `$[?foo(@)]`, err: `invalid JSONPath "$[?foo(@)]" at offset 3: unknown function "foo"`},
+		{query: `$[?match(@.a)]`, err: `invalid JSONPath "$[?match(@.a)]" at offset 3: match takes 2 argument(s)`},
+		{query: `$ x`, err: `invalid JSONPath "$ x" at offset 1: unexpected " x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Query(doc, tt.query)
+			if err == nil {
+				t.Fatalf("expected error %q", tt.err)
+			}
+			if err.Error() != tt.err {
+				t.Errorf("got error %q, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package jsonparser
+
+import "fmt"
+
+// WithLazy defers building the children of nested objects and arrays until
+// they are first accessed through Object, Array, Value or any function
+// traversing the element, such as Walk or Pretty. The input is still
+// validated up front, but documents of which only a small part is used
+// are parsed with less work and allocation.
+//
+// Lazily parsed elements are loaded in place on first access,
+// which is not safe for concurrent use. WithLazy has no effect
+// together with WithRecovery, WithReviver or DuplicateKeysReject.
+func WithLazy() Option {
+	return func(c *config) {
+		c.lazy = true
+	}
+}
+
+// lazyValue is the value of an object or array element whose children
+// are not built yet.
+type lazyValue struct {
+	// raw is the source of the element from its opening
+	// to its closing bracket.
+	raw   []byte
+	start Position
+	cfg   config
+	depth int
+	path  []string
+}
+
+func (p *parser) isLazy(r rune) bool {
+	return p.cfg.lazy && p.depth > 0 && (r == '{' || r == '[') &&
+		!p.cfg.recovery && p.cfg.duplicateKeys != DuplicateKeysReject &&
+		p.cfg.reviver == nil
+}
+
+// parseLazy validates an object or array and stores its source for load.
+func (p *parser) parseLazy() (*Element, error) {
+	start := p.r.pos()
+	offset, pin := p.r.mark()
+	defer p.r.release(pin)
+
+	kind := ObjectKind
+	if r, _ := p.r.peek(); r == '[' {
+		kind = ArrayKind
+	}
+
+	dropComments := p.dropComments
+	p.dropComments = true
+	err := p.handleValue(BaseHandler{})
+	p.dropComments = dropComments
+	if err != nil {
+		return nil, err
+	}
+
+	return &Element{
+		kind: kind,
+		value: &lazyValue{
+			raw:   p.r.slice(offset, p.r.offset),
+			start: start,
+			cfg:   p.cfg,
+			depth: p.depth,
+			path:  append([]string(nil), p.path...),
+		},
+	}, nil
+}
+
+// load builds the children of a lazily parsed element.
+func (e *Element) load() {
+	lv, ok := e.value.(*lazyValue)
+	if !ok {
+		return
+	}
+
+	p := &parser{
+		r: reader{
+			s:       lv.raw,
+			base:    lv.start.Offset,
+			offset:  lv.start.Offset,
+			line:    lv.start.Line,
+			col:     lv.start.Col - 1,
+			pin:     -1,
+			columns: lv.cfg.columns,
+		},
+		cfg:   lv.cfg,
+		depth: lv.depth,
+		path:  lv.path,
+	}
+
+	var (
+		el  *Element
+		err error
+	)
+	if p.r.read() == '{' {
+		el, err = p.parseObject()
+	} else {
+		el, err = p.parseArray()
+	}
+	if err != nil {
+		// the source was validated when it was stored
+		panic(fmt.Sprintf("loading lazy element: %v", err))
+	}
+
+	e.value = el.value
+	if el.comments != nil {
+		attachComments(&e.comments, nil, nil, el.comments.Dangling)
+	}
+}
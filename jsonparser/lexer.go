@@ -0,0 +1,182 @@
+package jsonparser
+
+import "fmt"
+
+// TokenKind is the type of a lexical token.
+type TokenKind uint8
+
+const (
+	EOFToken TokenKind = iota
+	ObjectStartToken
+	ObjectEndToken
+	ArrayStartToken
+	ArrayEndToken
+	StringToken
+	NumberToken
+	LiteralToken
+	ColonToken
+	CommaToken
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case EOFToken:
+		return "eof"
+	case ObjectStartToken:
+		return "{"
+	case ObjectEndToken:
+		return "}"
+	case ArrayStartToken:
+		return "["
+	case ArrayEndToken:
+		return "]"
+	case StringToken:
+		return "string"
+	case NumberToken:
+		return "number"
+	case LiteralToken:
+		return "literal"
+	case ColonToken:
+		return ":"
+	case CommaToken:
+		return ","
+	}
+	panic("unreachable")
+}
+
+// Token is a single lexical token of a JSON document.
+type Token struct {
+	Kind TokenKind
+	// Raw is the source text of the token, including the quotes of strings.
+	Raw []byte
+	// Line and Col are the 1-based position of the first character of the token.
+	Line int
+	Col  int
+}
+
+// Lexer splits a JSON document into tokens without checking
+// that the tokens form a valid document.
+type Lexer struct {
+	p *parser
+	// objects holds for every open bracket whether it opens an object,
+	// and last the kind of the last token, to tell keys from values.
+	objects []bool
+	last    TokenKind
+}
+
+// NewLexer returns a lexer reading tokens from b.
+func NewLexer(b []byte, opts ...Option) *Lexer {
+	return &Lexer{p: newParser(b, opts...)}
+}
+
+// Next returns the next token. At the end of the input it returns
+// a token of kind EOFToken.
+func (l *Lexer) Next() (Token, error) {
+	p := l.p
+	p.eatWhitespace()
+
+	line, col := p.r.line, p.r.col+1
+	start, pin := p.r.mark()
+	defer p.r.release(pin)
+
+	if p.r.isEOF() {
+		return Token{Kind: EOFToken, Line: line, Col: col}, nil
+	}
+
+	var (
+		kind TokenKind
+		err  error
+	)
+
+	r := p.r.read()
+	if p.cfg.unquotedKeys && isIdentifierStart(r) && l.atKey() {
+		// keys may start like literals, so the whole identifier is read
+		p.parseIdentifier(r)
+		return l.token(StringToken, start, line, col), nil
+	}
+	switch r {
+	case '{':
+		kind = ObjectStartToken
+	case '}':
+		kind = ObjectEndToken
+	case '[':
+		kind = ArrayStartToken
+	case ']':
+		kind = ArrayEndToken
+	case ':':
+		kind = ColonToken
+	case ',':
+		kind = CommaToken
+	case '"':
+		kind = StringToken
+		_, err = p.parseRawString()
+	case '\'':
+		if !p.cfg.singleQuotes {
+			err = p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+			break
+		}
+		kind = StringToken
+		_, err = p.parseSingleQuoted()
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		kind = NumberToken
+		_, err = p.parseNumber(r)
+	case '+', '.':
+		if !p.cfg.lenientNumbers {
+			err = p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+			break
+		}
+		kind = NumberToken
+		_, err = p.parseNumber(r)
+	case 'N', 'I':
+		if !p.cfg.nonFiniteNumbers {
+			err = p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+			break
+		}
+		kind = NumberToken
+		_, err = p.parseNumber(r)
+	case 't', 'f':
+		kind = LiteralToken
+		_, err = p.parseBool(r)
+	case 'n':
+		kind = LiteralToken
+		_, err = p.parseNull()
+	default:
+		err = p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+	}
+	if err != nil {
+		return Token{}, err
+	}
+
+	return l.token(kind, start, line, col), nil
+}
+
+// atKey reports whether the next token is in the position of a key,
+// where bare identifiers are read as strings with unquoted keys enabled.
+// Elsewhere they are rejected unless they are literals, as by Parse.
+func (l *Lexer) atKey() bool {
+	if len(l.objects) == 0 || !l.objects[len(l.objects)-1] {
+		return false
+	}
+	return l.last == ObjectStartToken || l.last == CommaToken
+}
+
+// token returns the token of kind read from start,
+// recording it to tell keys from values.
+func (l *Lexer) token(kind TokenKind, start, line, col int) Token {
+	switch kind {
+	case ObjectStartToken, ArrayStartToken:
+		l.objects = append(l.objects, kind == ObjectStartToken)
+	case ObjectEndToken, ArrayEndToken:
+		if len(l.objects) > 0 {
+			l.objects = l.objects[:len(l.objects)-1]
+		}
+	}
+	l.last = kind
+
+	return Token{
+		Kind: kind,
+		Raw:  l.p.r.slice(start, l.p.r.offset),
+		Line: line,
+		Col:  col,
+	}
+}
@@ -0,0 +1,87 @@
+package jsonparser
+
+import (
+	"slices"
+	"testing"
+)
+
+func lex(input string, opts ...Option) ([]TokenKind, error) {
+	l := NewLexer([]byte(input), opts...)
+	var kinds []TokenKind
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return kinds, err
+		}
+		if tok.Kind == EOFToken {
+			return kinds, nil
+		}
+		kinds = append(kinds, tok.Kind)
+	}
+}
+
+func TestLexerUnquotedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []TokenKind
+		wantErr bool
+	}{
+		{
+			name:  "identifier key",
+			input: `{foo: 1}`,
+			want:  []TokenKind{ObjectStartToken, StringToken, ColonToken, NumberToken, ObjectEndToken},
+		},
+		{
+			name:  "keys named like literals",
+			input: `{true: null, nullable: false, NaN: Infinity}`,
+			want: []TokenKind{
+				ObjectStartToken,
+				StringToken, ColonToken, LiteralToken, CommaToken,
+				StringToken, ColonToken, LiteralToken, CommaToken,
+				StringToken, ColonToken, NumberToken,
+				ObjectEndToken,
+			},
+		},
+		{
+			name:  "keys of nested objects",
+			input: `[{a: [1, {b: 2}]}, {c: 3}]`,
+			want: []TokenKind{
+				ArrayStartToken,
+				ObjectStartToken, StringToken, ColonToken,
+				ArrayStartToken, NumberToken, CommaToken,
+				ObjectStartToken, StringToken, ColonToken, NumberToken, ObjectEndToken,
+				ArrayEndToken, ObjectEndToken, CommaToken,
+				ObjectStartToken, StringToken, ColonToken, NumberToken, ObjectEndToken,
+				ArrayEndToken,
+			},
+		},
+		{name: "identifier array element", input: `[foo]`, wantErr: true},
+		{name: "identifier member value", input: `{a: foo}`, wantErr: true},
+		{name: "identifier document", input: `foo`, wantErr: true},
+		{name: "identifier after literal", input: `[true, $x]`, wantErr: true},
+	}
+
+	opts := []Option{WithUnquotedKeys(), WithNonFiniteNumbers()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lex(tt.input, opts...)
+			// the lexer accepts the tokens of the documents the parser accepts
+			if _, parseErr := ParseString(tt.input, opts...); (parseErr != nil) != tt.wantErr {
+				t.Fatalf("parse error %v, want error %v", parseErr, tt.wantErr)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got tokens %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got tokens %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package jsonparser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// WriteMarkdownTable writes an array of objects to w as a GitHub-flavored
+// Markdown table, one row per object, with the columns of WriteCSV.
+// Nested objects and arrays are written as minified JSON, and nulls
+// as empty cells.
+func WriteMarkdownTable(w io.Writer, e *Element) error {
+	header, rows, err := csvConfig{}.table(e)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	writeRow := func(cells []string) {
+		bw.WriteString("|")
+		for _, c := range cells {
+			bw.WriteString(" ")
+			bw.WriteString(markdownCell(c))
+			bw.WriteString(" |")
+		}
+		bw.WriteString("\n")
+	}
+
+	writeRow(header)
+	bw.WriteString("|")
+	for range header {
+		bw.WriteString(" --- |")
+	}
+	bw.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return bw.Flush()
+}
+
+var markdownCellReplacer = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>",
+)
+
+// markdownCell escapes the text of a table cell.
+func markdownCell(s string) string {
+	return markdownCellReplacer.Replace(s)
+}
@@ -0,0 +1,234 @@
+package jsonparser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MarshalOption configures Marshal and MarshalIndent.
+type MarshalOption func(*marshalConfig)
+
+type marshalConfig struct {
+	nonFinite bool
+}
+
+// WithNonFiniteFloats emits NaN and infinite floats as the NaN, Infinity
+// and -Infinity literals instead of failing. The output is then not valid
+// JSON, but can be read back with WithNonFiniteNumbers.
+func WithNonFiniteFloats() MarshalOption {
+	return func(c *marshalConfig) {
+		c.nonFinite = true
+	}
+}
+
+// Marshal returns the minified JSON encoding of v.
+func Marshal(v any, opts ...MarshalOption) ([]byte, error) {
+	el, err := FromValue(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(Minify(el)), nil
+}
+
+// MarshalIndent is like Marshal but produces the same output as Pretty.
+func MarshalIndent(v any, indent int, opts ...MarshalOption) ([]byte, error) {
+	el, err := FromValue(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(Pretty(el, indent)), nil
+}
+
+// FromValue converts a Go value into an element tree.
+//
+// Struct fields are encoded using the "json" tag with the same
+// semantics as encoding/json ("-", a custom name and "omitempty").
+// Nil pointers, interfaces, maps and slices become null.
+// Maps must have string keys.
+func FromValue(v any, opts ...MarshalOption) (*Element, error) {
+	var m marshaler
+	for _, opt := range opts {
+		opt(&m.cfg)
+	}
+	return m.valueToElement(reflect.ValueOf(v))
+}
+
+// ToValue converts an element tree into Go values: objects become
+// map[string]any, arrays []any, strings string and booleans bool.
+// Numbers become int64 if they are integers fitting into it and float64
+// otherwise. Nulls become nil. Of members with the same key,
+// the last one wins.
+func ToValue(e *Element) any {
+	e.load()
+	switch e.kind {
+	case ObjectKind:
+		members := e.value.([]Member)
+		m := make(map[string]any, len(members))
+		for _, member := range members {
+			m[member.Key()] = ToValue(member.value)
+		}
+		return m
+	case ArrayKind:
+		elements := e.value.([]*Element)
+		s := make([]any, len(elements))
+		for i, el := range elements {
+			s[i] = ToValue(el)
+		}
+		return s
+	case StringKind:
+		return unescape(e.value.([]byte))
+	case NumberKind:
+		if i, err := parseInt64(numberText(e.value)); err == nil {
+			return i
+		}
+		f, _ := e.Float64()
+		return f
+	case BooleanKind:
+		return e.value.(bool)
+	case NullKind:
+		return nil
+	}
+	panic("unreachable")
+}
+
+type marshaler struct {
+	cfg marshalConfig
+}
+
+func (m *marshaler) valueToElement(v reflect.Value) (*Element, error) {
+	if !v.IsValid() {
+		return &Element{kind: NullKind}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &Element{kind: NullKind}, nil
+		}
+		return m.valueToElement(v.Elem())
+	case reflect.Bool:
+		return &Element{kind: BooleanKind, value: v.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Element{kind: NumberKind, value: strconv.FormatInt(v.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Element{kind: NumberKind, value: strconv.FormatUint(v.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			if !m.cfg.nonFinite {
+				return nil, fmt.Errorf("unsupported value: %v", f)
+			}
+			return &Element{kind: NumberKind, value: nonFiniteText(f)}, nil
+		}
+		return &Element{kind: NumberKind, value: strconv.FormatFloat(f, 'g', -1, v.Type().Bits())}, nil
+	case reflect.String:
+		return &Element{kind: StringKind, value: escapeString(v.String())}, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return &Element{kind: NullKind}, nil
+		}
+		return m.sliceToElement(v)
+	case reflect.Array:
+		return m.sliceToElement(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return &Element{kind: NullKind}, nil
+		}
+		return m.mapToElement(v)
+	case reflect.Struct:
+		return m.structToElement(v)
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", v.Type())
+	}
+}
+
+func (m *marshaler) sliceToElement(v reflect.Value) (*Element, error) {
+	elements := make([]*Element, 0, v.Len())
+	for i := range v.Len() {
+		el, err := m.valueToElement(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, el)
+	}
+	return &Element{kind: ArrayKind, value: elements}, nil
+}
+
+func (m *marshaler) mapToElement(v reflect.Value) (*Element, error) {
+	if v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported map key type: %s", v.Type().Key())
+	}
+
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	members := make([]Member, 0, len(keys))
+	for _, k := range keys {
+		el, err := m.valueToElement(v.MapIndex(k))
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, Member{key: escapeString(k.String()), value: el})
+	}
+	return &Element{kind: ObjectKind, value: members}, nil
+}
+
+func (m *marshaler) structToElement(v reflect.Value) (*Element, error) {
+	var members []Member
+
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		if f.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			el, err := m.structToElement(fv)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, el.value.([]Member)...)
+			continue
+		}
+
+		if slices.Contains(strings.Split(opts, ","), "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		el, err := m.valueToElement(fv)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, Member{key: escapeString(name), value: el})
+	}
+
+	return &Element{kind: ObjectKind, value: members}, nil
+}
+
+func nonFiniteText(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return "NaN"
+}
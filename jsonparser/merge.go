@@ -0,0 +1,74 @@
+package jsonparser
+
+// ArrayMerge is how Merge combines arrays.
+type ArrayMerge uint8
+
+const (
+	// ReplaceArrays replaces the arrays of dst with the ones of src.
+	ReplaceArrays ArrayMerge = iota
+	// AppendArrays appends the elements of the arrays of src
+	// to the ones of dst.
+	AppendArrays
+	// MergeArraysByIndex merges the elements of the arrays of src into
+	// the elements of dst at the same indices, appending the rest.
+	MergeArraysByIndex
+)
+
+// NullMerge is how Merge treats the nulls of src.
+type NullMerge uint8
+
+const (
+	// KeepNulls sets the values of dst to the nulls of src.
+	KeepNulls NullMerge = iota
+	// DeleteNulls removes the object members of dst set to null in src,
+	// as JSON Merge Patches do.
+	DeleteNulls
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	Arrays ArrayMerge
+	Nulls  NullMerge
+}
+
+// Merge returns a new document of src merged into dst, leaving both
+// unchanged. The members of objects are merged recursively, arrays are
+// combined as opts.Arrays says, and other values of src replace the
+// ones of dst.
+func Merge(dst, src *Element, opts MergeOptions) *Element {
+	merged := dst.Clone()
+	merge(merged, src, opts)
+	return merged
+}
+
+// merge merges src into dst in place.
+func merge(dst, src *Element, opts MergeOptions) {
+	dst.load()
+	if members, ok := src.Object(); ok && dst.kind == ObjectKind {
+		for _, m := range members {
+			key := m.Key()
+			if opts.Nulls == DeleteNulls && m.value.IsNull() {
+				dst.RemoveMember(key)
+				continue
+			}
+			target, err := child(dst, key)
+			if err != nil {
+				dst.SetMember(key, m.value.Clone())
+				continue
+			}
+			merge(target, m.value, opts)
+		}
+		return
+	}
+	if elements, ok := src.Array(); ok && opts.Arrays != ReplaceArrays && dst.kind == ArrayKind {
+		for i, e := range elements {
+			if existing := dst.value.([]*Element); opts.Arrays == MergeArraysByIndex && i < len(existing) {
+				merge(existing[i], e, opts)
+				continue
+			}
+			dst.Append(e.Clone())
+		}
+		return
+	}
+	dst.ReplaceValue(src.Clone())
+}
@@ -0,0 +1,29 @@
+package jsonparser
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7386) patch to doc
+// in place: the members of an object patch are merged into doc
+// recursively, removing the members set to null, and any other patch
+// replaces doc.
+func ApplyMergePatch(doc, patch *Element) {
+	members, ok := patch.Object()
+	if !ok {
+		doc.ReplaceValue(patch.Clone())
+		return
+	}
+	if doc.Kind() != ObjectKind {
+		doc.ReplaceValue(&Element{kind: ObjectKind, value: []Member{}})
+	}
+	for _, m := range members {
+		key := m.Key()
+		if m.value.IsNull() {
+			doc.RemoveMember(key)
+			continue
+		}
+		target, err := child(doc, key)
+		if err != nil {
+			target = &Element{kind: NullKind}
+			doc.SetMember(key, target)
+		}
+		ApplyMergePatch(target, m.value)
+	}
+}
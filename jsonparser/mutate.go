@@ -0,0 +1,127 @@
+package jsonparser
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// SetMember sets the value of the member with the given (unescaped) key,
+// appending a new member if the object has none.
+func (e *Element) SetMember(key string, val *Element) error {
+	members, err := e.members("set member")
+	if err != nil {
+		return err
+	}
+
+	for i, m := range members {
+		if unescape(m.key) == key {
+			members[i].value = val
+			return nil
+		}
+	}
+
+	e.value = append(members, Member{key: escapeString(key), value: val})
+	return nil
+}
+
+// RemoveMember removes all members with the given (unescaped) key.
+func (e *Element) RemoveMember(key string) error {
+	members, err := e.members("remove member")
+	if err != nil {
+		return err
+	}
+
+	e.value = slices.DeleteFunc(members, func(m Member) bool {
+		return unescape(m.key) == key
+	})
+	return nil
+}
+
+// Append adds el to the end of the array.
+func (e *Element) Append(el *Element) error {
+	elements, err := e.elements("append")
+	if err != nil {
+		return err
+	}
+
+	e.value = append(elements, el)
+	return nil
+}
+
+// InsertAt inserts el into the array at index i, shifting later elements.
+func (e *Element) InsertAt(i int, el *Element) error {
+	elements, err := e.elements("insert")
+	if err != nil {
+		return err
+	}
+
+	if i < 0 || i > len(elements) {
+		return fmt.Errorf("index %d out of range [0:%d]", i, len(elements))
+	}
+
+	e.value = slices.Insert(elements, i, el)
+	return nil
+}
+
+// RemoveAt removes the element at index i from the array, shifting later elements.
+func (e *Element) RemoveAt(i int) error {
+	elements, err := e.elements("remove")
+	if err != nil {
+		return err
+	}
+
+	if i < 0 || i >= len(elements) {
+		return fmt.Errorf("index %d out of range [0:%d]", i, len(elements))
+	}
+
+	e.value = slices.Delete(elements, i, i+1)
+	return nil
+}
+
+// ReplaceValue replaces the element in place with v,
+// so that every reference to e observes the new value.
+func (e *Element) ReplaceValue(v *Element) {
+	*e = *v
+}
+
+// Clone returns a deep copy of the element, which can be modified
+// without affecting e.
+func (e *Element) Clone() *Element {
+	e.load()
+	c := *e
+	switch v := e.value.(type) {
+	case []Member:
+		members := make([]Member, len(v))
+		for i, m := range v {
+			members[i] = m
+			members[i].value = m.value.Clone()
+		}
+		c.value = members
+	case []*Element:
+		elements := make([]*Element, len(v))
+		for i, el := range v {
+			elements[i] = el.Clone()
+		}
+		c.value = elements
+	case *big.Rat:
+		c.value = new(big.Rat).Set(v)
+	}
+	return &c
+}
+
+func (e *Element) members(op string) ([]Member, error) {
+	members, ok := e.Object()
+	if !ok {
+		return nil, fmt.Errorf("cannot %s: %s is not an object", op, e.kind)
+	}
+	return members, nil
+}
+
+func (e *Element) elements(op string) ([]*Element, error) {
+	elements, ok := e.Array()
+	if !ok {
+		return nil, fmt.Errorf("cannot %s: %s is not an array", op, e.kind)
+	}
+	return elements, nil
+}
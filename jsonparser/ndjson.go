@@ -0,0 +1,71 @@
+package jsonparser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// LineDecoder reads newline-delimited JSON (NDJSON, JSON Lines), where every
+// non-blank line holds one JSON value. Positions in returned elements and
+// errors refer to the whole input, so a failing record is reported with its line.
+type LineDecoder struct {
+	r      *bufio.Reader
+	opts   []Option
+	line   int
+	offset int
+}
+
+// NewLineDecoder returns a decoder reading records from r.
+func NewLineDecoder(r io.Reader, opts ...Option) *LineDecoder {
+	return &LineDecoder{r: bufio.NewReader(r), opts: opts}
+}
+
+// Decode parses the next record, skipping blank lines.
+// It returns io.EOF when the input is exhausted.
+func (d *LineDecoder) Decode() (*Element, error) {
+	for {
+		b, err := d.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return nil, err
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		line, offset := d.line+1, d.offset
+		d.line++
+		d.offset += len(b)
+
+		b = bytes.TrimSuffix(bytes.TrimSuffix(b, []byte{'\n'}), []byte{'\r'})
+		if len(bytes.TrimLeft(b, " \t")) == 0 {
+			continue
+		}
+
+		p := newParser(b, d.opts...)
+		p.r.base, p.r.offset, p.r.line = offset, offset, line
+		return p.parse()
+	}
+}
+
+// ParseLines parses newline-delimited JSON and returns its records as an array.
+func ParseLines(r io.Reader, opts ...Option) (*Element, error) {
+	d := NewLineDecoder(r, opts...)
+
+	var elements []*Element
+	for {
+		el, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, el)
+	}
+
+	return &Element{
+		kind:  ArrayKind,
+		value: elements,
+	}, nil
+}
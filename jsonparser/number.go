@@ -0,0 +1,305 @@
+package jsonparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// NumberMode defines how number elements are decoded.
+type NumberMode uint8
+
+const (
+	// NumberRaw keeps the source text of numbers as a string, which is the default.
+	NumberRaw NumberMode = iota
+	// NumberFloat64 decodes numbers into float64.
+	NumberFloat64
+	// NumberInt64 decodes numbers into int64, failing on
+	// non-integer values and on overflow.
+	NumberInt64
+	// NumberJSON decodes numbers into json.Number.
+	NumberJSON
+	// NumberDecimal decodes numbers into *big.Rat, which represents
+	// every decimal number exactly.
+	NumberDecimal
+)
+
+// maxDecimalExponent bounds the exponent of numbers decoded by
+// NumberDecimal, since the size of the result grows with it.
+const maxDecimalExponent = 10000
+
+// maxFixedExponent bounds the exponent of numbers written by
+// WithFixedDecimals to the range of float64, since the length
+// of the output grows with it.
+const maxFixedExponent = 308
+
+// WithNumberMode sets how number elements are decoded.
+func WithNumberMode(mode NumberMode) Option {
+	return func(c *config) {
+		c.numberMode = mode
+	}
+}
+
+// WithExactNumbers rejects numbers that change their value when converted
+// to float64, such as integers beyond 2^53, failing with ErrInexactNumber.
+// A number is exact if it equals the shortest decimal form of the float64
+// closest to it, so 0.1 is accepted. This is the precision of numbers in
+// JavaScript and in many JSON libraries.
+func WithExactNumbers() Option {
+	return func(c *config) {
+		c.exactNumbers = true
+	}
+}
+
+func (p *parser) decodeNumber(raw string) (any, error) {
+	if p.cfg.exactNumbers && !exactNumber(raw) {
+		return nil, p.syntaxError(fmt.Errorf("%w: %s", ErrInexactNumber, raw))
+	}
+
+	switch p.cfg.numberMode {
+	case NumberFloat64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, p.syntaxError(fmt.Errorf("number %s overflows float64", raw))
+		}
+		return f, nil
+	case NumberInt64:
+		i, err := parseInt64(raw)
+		if err != nil {
+			return nil, p.syntaxError(err)
+		}
+		return i, nil
+	case NumberJSON:
+		return json.Number(raw), nil
+	case NumberDecimal:
+		r, err := parseDecimal(raw)
+		if err != nil {
+			return nil, p.syntaxError(err)
+		}
+		return r, nil
+	default:
+		return raw, nil
+	}
+}
+
+// exactNumber reports whether the number text has the value of the
+// shortest decimal form of the closest float64. Non-finite literals are exact.
+func exactNumber(raw string) bool {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return true
+	}
+	exact, err := parseDecimal(raw)
+	if err != nil {
+		return false
+	}
+	shortest, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return exact.Cmp(shortest) == 0
+}
+
+func parseDecimal(raw string) (*big.Rat, error) {
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(raw[i+1:])
+		if err != nil || exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return nil, fmt.Errorf("number %s exceeds the decimal exponent limit", raw)
+		}
+	}
+	r, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		return nil, fmt.Errorf("number %s cannot be represented as a decimal", raw)
+	}
+	return r, nil
+}
+
+func parseInt64(raw string) (int64, error) {
+	i, err := strconv.ParseInt(raw, 10, 64)
+	if err == nil {
+		return i, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("number %s overflows int64", raw)
+	}
+
+	// fractions and exponents are fine as long as the value is integral
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) || f != math.Trunc(f) {
+		return 0, fmt.Errorf("number %s is not an integer", raw)
+	}
+	if err != nil || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("number %s overflows int64", raw)
+	}
+	return int64(f), nil
+}
+
+func numberText(v any) string {
+	switch n := v.(type) {
+	case string:
+		return n
+	case json.Number:
+		return n.String()
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case float64:
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nonFiniteText(n)
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	case *big.Rat:
+		return decimalText(n)
+	}
+	panic("unreachable")
+}
+
+// NumberFormat defines how number elements are written
+// by Minify, Pretty and their variants.
+type NumberFormat uint8
+
+const (
+	// NumberPreserve writes numbers as decoded, which is the source text
+	// for NumberRaw. It is the default.
+	NumberPreserve NumberFormat = iota
+	// NumberNormalExponent writes exponents with a lower case e,
+	// without a plus sign or leading zeros, and drops zero exponents.
+	NumberNormalExponent
+	// NumberShortest writes the shortest text that parses into the same
+	// float64, formatted as in ECMAScript. Numbers out of the float64
+	// range are written as decoded.
+	NumberShortest
+)
+
+// formatNumber returns the text of a number element
+// in the given format, or with the given number of decimal places
+// if it is not negative.
+func formatNumber(v any, format NumberFormat, places int) string {
+	text := numberText(v)
+	if places >= 0 {
+		if i := strings.IndexAny(text, "eE"); i >= 0 {
+			exp, err := strconv.Atoi(text[i+1:])
+			if err != nil || exp > maxFixedExponent || exp < -maxFixedExponent {
+				return text
+			}
+		}
+		r, err := parseDecimal(text)
+		if err != nil {
+			// NaN and infinities
+			return text
+		}
+		return r.FloatString(places)
+	}
+
+	switch format {
+	case NumberNormalExponent:
+		return normalizeExponent(text)
+	case NumberShortest:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return text
+		}
+		return es6NumberText(f)
+	}
+	return text
+}
+
+func normalizeExponent(text string) string {
+	i := strings.IndexAny(text, "eE")
+	if i < 0 {
+		return text
+	}
+	mantissa, exp := text[:i], text[i+1:]
+
+	var sign string
+	if exp != "" && (exp[0] == '+' || exp[0] == '-') {
+		if exp[0] == '-' {
+			sign = "-"
+		}
+		exp = exp[1:]
+	}
+	exp = strings.TrimLeft(exp, "0")
+	if exp == "" {
+		return mantissa
+	}
+	return mantissa + "e" + sign + exp
+}
+
+// decimalText formats r as a decimal number without loss,
+// which is possible for every number parsed from decimal text.
+func decimalText(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// the number of fractional digits is the larger exponent
+	// of the factors 2 and 5 of the denominator
+	d := new(big.Int).Set(r.Denom())
+	twos := int(d.TrailingZeroBits())
+	d.Rsh(d, uint(twos))
+	fives := 0
+	five, m := big.NewInt(5), new(big.Int)
+	for {
+		q, rem := new(big.Int).QuoRem(d, five, m)
+		if rem.Sign() != 0 {
+			break
+		}
+		d, fives = q, fives+1
+	}
+	return r.FloatString(max(twos, fives))
+}
+
+// Int64 returns the value of a number element as int64.
+// It reports false if the element is not a number or
+// its value is not an integer representable as int64.
+func (e *Element) Int64() (int64, bool) {
+	if e.kind != NumberKind {
+		return 0, false
+	}
+	switch n := e.value.(type) {
+	case int64:
+		return n, true
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	i, err := parseInt64(numberText(e.value))
+	return i, err == nil
+}
+
+// Float64 returns the value of a number element as float64.
+// It reports false if the element is not a number or it overflows float64.
+func (e *Element) Float64() (float64, bool) {
+	if e.kind != NumberKind {
+		return 0, false
+	}
+	switch n := e.value.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case *big.Rat:
+		f, _ := n.Float64()
+		return f, !math.IsInf(f, 0)
+	}
+	f, err := strconv.ParseFloat(numberText(e.value), 64)
+	return f, err == nil
+}
+
+// Decimal returns the exact value of a number element.
+// It reports false if the element is not a number or it is NaN or infinite.
+func (e *Element) Decimal() (*big.Rat, bool) {
+	if e.kind != NumberKind {
+		return nil, false
+	}
+	if n, ok := e.value.(*big.Rat); ok {
+		return new(big.Rat).Set(n), true
+	}
+	r, err := parseDecimal(numberText(e.value))
+	return r, err == nil
+}
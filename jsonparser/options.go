@@ -0,0 +1,173 @@
+package jsonparser
+
+// Option configures the parser.
+type Option func(*config)
+
+// DefaultMaxDepth is the nesting limit applied unless WithMaxDepth is given.
+const DefaultMaxDepth = 10000
+
+type config struct {
+	duplicateKeys    DuplicateKeyPolicy
+	numberMode       NumberMode
+	maxDepth         int
+	maxInputSize     int64
+	recovery         bool
+	invalidUTF8      UTF8Policy
+	comments         bool
+	trailingCommas   bool
+	nonFiniteNumbers bool
+	controlChars     bool
+	singleQuotes     bool
+	unquotedKeys     bool
+	lenientNumbers   bool
+	exactNumbers     bool
+	dialect          Dialect
+	paths            [][]string
+	lazy             bool
+	reviver          Reviver
+	columns          columns
+	literalHooks     []LiteralHook
+
+	// err is an invalid option argument, reported when parsing.
+	err error
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		maxDepth: DefaultMaxDepth,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// DuplicateKeyPolicy defines how repeated keys of an object are handled.
+// Keys are compared after unescaping.
+type DuplicateKeyPolicy uint8
+
+const (
+	// DuplicateKeysAllow keeps every member, which is the default.
+	DuplicateKeysAllow DuplicateKeyPolicy = iota
+	// DuplicateKeysReject fails parsing on a repeated key.
+	DuplicateKeysReject
+	// DuplicateKeysFirstWins keeps the first member with a given key.
+	DuplicateKeysFirstWins
+	// DuplicateKeysLastWins keeps the value of the last member with a given key.
+	DuplicateKeysLastWins
+)
+
+// WithDuplicateKeyPolicy sets how repeated object keys are handled.
+func WithDuplicateKeyPolicy(policy DuplicateKeyPolicy) Option {
+	return func(c *config) {
+		c.duplicateKeys = policy
+	}
+}
+
+// WithMaxDepth limits the nesting depth of objects and arrays.
+// A value of 0 or less disables the limit.
+func WithMaxDepth(n int) Option {
+	return func(c *config) {
+		c.maxDepth = n
+	}
+}
+
+// WithMaxInputSize limits the size of the input in bytes.
+// Larger input fails with ErrInputTooLarge. A value of 0 or less,
+// the default, disables the limit.
+func WithMaxInputSize(n int64) Option {
+	return func(c *config) {
+		c.maxInputSize = n
+	}
+}
+
+// WithRecovery makes the parser continue after syntax errors by skipping
+// to the next ',', '}' or ']'. Parsing then returns the partial document
+// together with a SyntaxErrors error listing every error found.
+func WithRecovery() Option {
+	return func(c *config) {
+		c.recovery = true
+	}
+}
+
+// UTF8Policy defines how invalid UTF-8 inside of strings is handled.
+type UTF8Policy uint8
+
+const (
+	// UTF8PassThrough keeps invalid bytes untouched, which is the default.
+	UTF8PassThrough UTF8Policy = iota
+	// UTF8Reject fails parsing on invalid UTF-8.
+	UTF8Reject
+	// UTF8Replace replaces each invalid byte sequence with U+FFFD.
+	UTF8Replace
+)
+
+// WithInvalidUTF8 sets how invalid UTF-8 inside of strings is handled.
+func WithInvalidUTF8(policy UTF8Policy) Option {
+	return func(c *config) {
+		c.invalidUTF8 = policy
+	}
+}
+
+// WithComments allows // line and /* block */ comments wherever
+// whitespace is allowed, as in JSONC files.
+func WithComments() Option {
+	return func(c *config) {
+		c.comments = true
+	}
+}
+
+// WithAllowTrailingCommas allows a comma after the last element
+// of an array or the last member of an object.
+func WithAllowTrailingCommas() Option {
+	return func(c *config) {
+		c.trailingCommas = true
+	}
+}
+
+// WithNonFiniteNumbers accepts the NaN, Infinity and -Infinity literals
+// as emitted by Python and JavaScript, parsing them into number elements.
+// With WithLenientNumbers, +Infinity is accepted as well.
+func WithNonFiniteNumbers() Option {
+	return func(c *config) {
+		c.nonFiniteNumbers = true
+	}
+}
+
+// WithAllowControlChars accepts raw control characters, such as tabs
+// and line breaks, inside of strings. They are stored escaped,
+// so formatting the document produces valid JSON.
+func WithAllowControlChars() Option {
+	return func(c *config) {
+		c.controlChars = true
+	}
+}
+
+// WithSingleQuotes accepts 'single-quoted' strings and keys, as found
+// in JavaScript object literals. Inside of them a single quote is
+// escaped as \' and a double quote needs no escaping.
+// They are stored as double-quoted strings.
+func WithSingleQuotes() Option {
+	return func(c *config) {
+		c.singleQuotes = true
+	}
+}
+
+// WithUnquotedKeys accepts object keys written as bare identifiers,
+// such as {foo: 1}, as in JavaScript object literals.
+// Formatting the document emits them quoted.
+func WithUnquotedKeys() Option {
+	return func(c *config) {
+		c.unquotedKeys = true
+	}
+}
+
+// WithLenientNumbers relaxes the number grammar to accept leading zeros,
+// a leading '+' and a decimal point without digits before or after it,
+// as in 007, +3, .5 and 5. Numbers are normalized to the strict grammar,
+// so formatting the document emits 7, 3, 0.5 and 5.
+func WithLenientNumbers() Option {
+	return func(c *config) {
+		c.lenientNumbers = true
+	}
+}
@@ -0,0 +1,1162 @@
+package jsonparser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parse parses a single JSON document.
+//
+// A leading byte order mark is skipped and UTF-16 or UTF-32 input,
+// detected by its BOM or its pattern of zero bytes, is transcoded to UTF-8
+// before parsing. Source positions refer to the UTF-8 text.
+func Parse(b []byte, opts ...Option) (*Element, error) {
+	return newParser(b, opts...).parse()
+}
+
+// ParseString parses a single JSON document from s.
+func ParseString(s string, opts ...Option) (*Element, error) {
+	return Parse([]byte(s), opts...)
+}
+
+// ParseBytes parses a single JSON document from b. It is the same as Parse.
+func ParseBytes(b []byte, opts ...Option) (*Element, error) {
+	return Parse(b, opts...)
+}
+
+// ParseFile parses a single JSON document read from the file at path.
+func ParseFile(path string, opts ...Option) (*Element, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return newStreamParser(f, opts...).parse()
+}
+
+// ParsePrefix parses a single JSON value at the start of b, ignoring
+// leading whitespace, and returns the input following the value
+// instead of requiring the rest of b to be empty.
+func ParsePrefix(b []byte, opts ...Option) (el *Element, rest []byte, err error) {
+	p := newParser(b, opts...)
+	p.eatWhitespace()
+	if el, err = p.parseValue(); err != nil {
+		return nil, nil, err
+	}
+	rest = p.r.s[p.r.offset:]
+	if len(p.errs) != 0 {
+		return el, rest, p.errs
+	}
+	return el, rest, nil
+}
+
+// ParseAt parses a single JSON value starting at the byte offset in b,
+// ignoring leading whitespace and the input following the value.
+// Positions in the returned element and errors refer to the whole of b,
+// which must be UTF-8 encoded.
+func ParseAt(b []byte, offset int, opts ...Option) (*Element, error) {
+	if offset < 0 || offset > len(b) {
+		return nil, fmt.Errorf("offset %d out of range [0:%d]", offset, len(b))
+	}
+
+	cfg := newConfig(opts)
+	lineStart := bytes.LastIndexByte(b[:offset], '\n') + 1
+	p := &parser{
+		r: reader{
+			s:       b,
+			line:    1 + bytes.Count(b[:offset], []byte{'\n'}),
+			col:     cfg.columns.count(b[lineStart:offset]),
+			offset:  offset,
+			pin:     -1,
+			columns: cfg.columns,
+		},
+		cfg: cfg,
+	}
+
+	p.eatWhitespace()
+	el, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.errs) != 0 {
+		return el, p.errs
+	}
+	return el, nil
+}
+
+// ParseContext is like Parse but aborts with ctx.Err()
+// once the context is done.
+func ParseContext(ctx context.Context, b []byte, opts ...Option) (*Element, error) {
+	p := newParser(b, opts...)
+	p.ctx = ctx
+	return p.parse()
+}
+
+// Parser parses a JSON document read incrementally from an io.Reader.
+type Parser struct {
+	p *parser
+}
+
+// NewReaderParser returns a parser that reads the document from r.
+// Input is buffered in chunks as the parser advances, so the document
+// does not have to be loaded into memory up front.
+// The input encoding is detected as described for Parse.
+func NewReaderParser(r io.Reader, opts ...Option) *Parser {
+	return &Parser{p: newStreamParser(r, opts...)}
+}
+
+// Parse parses a single JSON document from the underlying reader.
+func (p *Parser) Parse() (*Element, error) {
+	return p.p.parse()
+}
+
+// ParseContext is like Parse but aborts with ctx.Err()
+// once the context is done.
+func (p *Parser) ParseContext(ctx context.Context) (*Element, error) {
+	p.p.ctx = ctx
+	return p.p.parse()
+}
+
+// ErrInputTooLarge is returned when the input exceeds the limit set by WithMaxInputSize.
+var ErrInputTooLarge = errors.New("input too large")
+
+func inputTooLarge(limit int64) error {
+	return fmt.Errorf("%w: exceeds %d bytes", ErrInputTooLarge, limit)
+}
+
+// contextCheckInterval is the number of values parsed between context checks.
+const contextCheckInterval = 1024
+
+type parser struct {
+	r     reader
+	cfg   config
+	depth int
+	errs  SyntaxErrors
+	// pending are the comments read but not yet attached to a node.
+	pending []comment
+	// dropComments discards comments instead of keeping them
+	// for attachment, when no tree is built.
+	dropComments bool
+
+	ctx   context.Context
+	steps int
+
+	// path holds the reference tokens of the value being parsed
+	// when selecting values with WithPaths or reviving them.
+	path []string
+}
+
+func newParser(s []byte, opts ...Option) *parser {
+	cfg := newConfig(opts)
+	return &parser{
+		r:   reader{s: toUTF8(s), line: 1, col: 0, pin: -1, columns: cfg.columns},
+		cfg: cfg,
+	}
+}
+
+func newStreamParser(r io.Reader, opts ...Option) *parser {
+	cfg := newConfig(opts)
+	return &parser{
+		r:   reader{src: &decodingReader{src: r}, limit: cfg.maxInputSize, line: 1, col: 0, pin: -1, columns: cfg.columns},
+		cfg: cfg,
+	}
+}
+
+func (p *parser) parse() (*Element, error) {
+	if p.ctx != nil && p.ctx.Err() != nil {
+		return nil, p.ctx.Err()
+	}
+	if p.cfg.err != nil {
+		return nil, p.cfg.err
+	}
+	if p.r.src == nil && p.cfg.maxInputSize > 0 && int64(len(p.r.s)) > p.cfg.maxInputSize {
+		return nil, inputTooLarge(p.cfg.maxInputSize)
+	}
+
+	var (
+		root *Element
+		err  error
+	)
+	if p.cfg.dialect == DialectHJSON {
+		root, err = p.parseHJSONRoot()
+	} else {
+		root, err = p.parseRoot()
+	}
+	if err := p.readErr(); err != nil {
+		return nil, err
+	}
+	if err == nil && len(p.errs) != 0 {
+		return root, p.errs
+	}
+	return root, err
+}
+
+// readErr returns the error the underlying reader failed with, if any.
+func (p *parser) readErr() error {
+	if p.r.err != nil && p.r.err != io.EOF {
+		return p.r.err
+	}
+	return nil
+}
+
+func (p *parser) parseRoot() (*Element, error) {
+	p.eatWhitespace()
+	_, leading := p.takeComments(0)
+	root, err := p.parseValue()
+	if err != nil && !p.tryRecover(err) {
+		return nil, err
+	}
+	p.eatWhitespace()
+	if root != nil {
+		sameLine, rest := p.takeComments(root.span.End.Line)
+		attachComments(&root.comments, leading, append(sameLine, rest...), nil)
+		root = p.revive(root)
+	}
+	if !p.r.isEOF() {
+		if err := p.expectedError("eof", p.r.read()); !p.tryRecover(err) {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+func (p *parser) parseValue() (el *Element, err error) {
+	if err := p.checkContext(); err != nil {
+		return nil, err
+	}
+
+	start := p.r.pos()
+
+	// In recovery mode a missing value must not swallow the delimiter
+	// that follows it, so that parsing can resume from there.
+	if r, _ := p.r.peek(); p.cfg.recovery && (r == ',' || r == ']' || r == '}') {
+		return nil, p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+	}
+
+	if r, _ := p.r.peek(); p.isHookLiteral(r) {
+		if el, err = p.parseHookLiteral(); err != nil {
+			return nil, err
+		}
+		el.span = Span{Start: start, End: p.r.pos()}
+		return el, nil
+	}
+
+	if r, _ := p.r.peek(); p.isLazy(r) {
+		if el, err = p.parseLazy(); err != nil {
+			return nil, err
+		}
+		el.span = Span{Start: start, End: p.r.pos()}
+		return el, nil
+	}
+
+	r := p.r.read()
+	switch r {
+	case '{':
+		el, err = p.parseObject()
+	case '[':
+		el, err = p.parseArray()
+	case '"':
+		el, err = p.parseString()
+	case '\'':
+		if !p.cfg.singleQuotes {
+			return el, p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+		}
+		el, err = p.parseSingleQuotedString()
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		el, err = p.parseNumber(r)
+	case 't', 'f':
+		el, err = p.parseBool(r)
+	case 'n':
+		el, err = p.parseNull()
+	case '+', '.':
+		if !p.cfg.lenientNumbers {
+			return el, p.syntaxError(fmt.Errorf("unexpected token: %q", r))
+		}
+		el, err = p.parseNumber(r)
+	case 'N', 'I':
+		if p.cfg.nonFiniteNumbers {
+			el, err = p.parseNumber(r)
+			break
+		}
+		fallthrough
+	default:
+		return el, p.syntaxError(
+			fmt.Errorf("unexpected token: %q", r),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	el.span = Span{Start: start, End: p.r.pos()}
+	return el, nil
+}
+
+func (p *parser) parseObject() (*Element, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.eatWhitespace()
+
+	var (
+		members []Member
+		seen    map[string]int
+	)
+
+	for n := 0; !p.r.isEOF(); n++ {
+		if r, _ := p.r.peek(); r == '}' || r == ']' && p.cfg.recovery {
+			break
+		}
+
+		var prev *Member
+		if len(members) != 0 {
+			prev = &members[len(members)-1]
+		}
+
+		member, err := p.parseObjectMember(n, prev)
+		if err == nil && member == nil {
+			break
+		}
+		if err == nil && member.value == nil {
+			// skipped by WithPaths or dropped by the reviver
+			continue
+		}
+
+		if err == nil && p.cfg.duplicateKeys != DuplicateKeysAllow {
+			if seen == nil {
+				seen = make(map[string]int)
+			}
+			key := unescape(member.key)
+			if i, ok := seen[key]; ok {
+				switch p.cfg.duplicateKeys {
+				case DuplicateKeysReject:
+					err = p.syntaxError(fmt.Errorf("%w %q", ErrDuplicateKey, key))
+				case DuplicateKeysFirstWins:
+					continue
+				case DuplicateKeysLastWins:
+					members[i].value = member.value
+					continue
+				}
+			} else {
+				seen[key] = len(members)
+			}
+		}
+
+		if err != nil {
+			if !p.tryRecover(err) {
+				return nil, err
+			}
+			p.sync()
+			continue
+		}
+
+		members = append(members, *member)
+	}
+
+	var dangling []string
+	if len(members) != 0 {
+		last := &members[len(members)-1]
+		var trailing []string
+		trailing, dangling = p.takeComments(last.value.span.End.Line)
+		attachComments(&last.comments, nil, trailing, nil)
+	} else {
+		_, dangling = p.takeComments(0)
+	}
+
+	if err := p.closeContainer('}'); err != nil {
+		return nil, err
+	}
+
+	el := &Element{
+		kind:  ObjectKind,
+		value: members,
+	}
+	attachComments(&el.comments, nil, nil, dangling)
+	return el, nil
+}
+
+func (p *parser) parseObjectMember(n int, prev *Member) (*Member, error) {
+	if n != 0 {
+		if err := p.expectComma(); err != nil {
+			return nil, err
+		}
+		if p.isTrailingComma('}') {
+			return nil, nil
+		}
+	}
+
+	var leading []string
+	if prev != nil {
+		var trailing []string
+		trailing, leading = p.takeComments(prev.value.span.End.Line)
+		attachComments(&prev.comments, nil, trailing, nil)
+	} else {
+		_, leading = p.takeComments(0)
+	}
+
+	member, err := p.parseMember()
+	if err != nil {
+		return nil, err
+	}
+	if member != nil {
+		attachComments(&member.comments, leading, nil, nil)
+	}
+
+	// In recovery mode the closing brace was already checked by the caller.
+	if member == nil && (n != 0 || p.cfg.recovery) {
+		return nil, p.syntaxError(fmt.Errorf("expected object member"))
+	}
+
+	return member, nil
+}
+
+func (p *parser) parseMember() (*Member, error) {
+	start := p.r.pos()
+	key, err := p.parseKey()
+	if key == nil || err != nil {
+		return nil, err
+	}
+	keySpan := Span{Start: start, End: p.r.pos()}
+	p.eatWhitespace()
+
+	if r := p.r.read(); r != ':' {
+		return nil, p.expectedError(":", r)
+	}
+
+	p.eatWhitespace()
+
+	if p.tracksPath() {
+		p.path = append(p.path, unescape(key))
+		defer p.popPath()
+		if !p.selected() {
+			p.skipValue()
+			p.eatWhitespace()
+			return &Member{key: key, keySpan: keySpan}, nil
+		}
+	}
+
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	p.eatWhitespace()
+
+	return &Member{
+		key:     key,
+		keySpan: keySpan,
+		value:   p.revive(val),
+	}, nil
+}
+
+// parseKey parses the key of an object member and returns it escaped,
+// or nil if the input does not start with a key.
+func (p *parser) parseKey() ([]byte, error) {
+	r, _ := p.r.peek()
+
+	quoted := r == '"' || r == '\'' && p.cfg.singleQuotes
+	if !quoted && (!p.cfg.unquotedKeys || !isIdentifierStart(r)) {
+		return nil, nil
+	}
+
+	p.r.read()
+
+	switch r {
+	case '"':
+		return p.parseRawString()
+	case '\'':
+		s, err := p.parseSingleQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return escapeString(s), nil
+	default:
+		return escapeString(p.parseIdentifier(r)), nil
+	}
+}
+
+func (p *parser) parseArray() (*Element, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.eatWhitespace()
+
+	var elements []*Element
+
+	for n := 0; !p.r.isEOF(); n++ {
+		if r, _ := p.r.peek(); r == ']' || r == '}' && p.cfg.recovery {
+			break
+		}
+
+		var prev *Element
+		if len(elements) != 0 {
+			prev = elements[len(elements)-1]
+		}
+
+		el, err := p.parseArrayElement(n, prev)
+		if err != nil {
+			if !p.tryRecover(err) {
+				return nil, err
+			}
+			p.sync()
+			continue
+		}
+		if el == nil {
+			// a trailing comma, or a value skipped by WithPaths
+			// or dropped by the reviver
+			continue
+		}
+		elements = append(elements, el)
+	}
+
+	p.eatWhitespace()
+
+	var dangling []string
+	if len(elements) != 0 {
+		last := elements[len(elements)-1]
+		var trailing []string
+		trailing, dangling = p.takeComments(last.span.End.Line)
+		attachComments(&last.comments, nil, trailing, nil)
+	} else {
+		_, dangling = p.takeComments(0)
+	}
+
+	if err := p.closeContainer(']'); err != nil {
+		return nil, err
+	}
+
+	el := &Element{
+		kind:  ArrayKind,
+		value: elements,
+	}
+	attachComments(&el.comments, nil, nil, dangling)
+	return el, nil
+}
+
+func (p *parser) parseArrayElement(n int, prev *Element) (*Element, error) {
+	if n != 0 {
+		if err := p.expectComma(); err != nil {
+			return nil, err
+		}
+		if p.isTrailingComma(']') {
+			return nil, nil
+		}
+	}
+
+	var leading []string
+	if prev != nil {
+		var trailing []string
+		trailing, leading = p.takeComments(prev.span.End.Line)
+		attachComments(&prev.comments, nil, trailing, nil)
+	} else {
+		_, leading = p.takeComments(0)
+	}
+
+	if p.tracksPath() {
+		p.path = append(p.path, strconv.Itoa(n))
+		defer p.popPath()
+		if !p.selected() {
+			p.skipValue()
+			p.eatWhitespace()
+			return nil, nil
+		}
+	}
+
+	el, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	attachComments(&el.comments, leading, nil, nil)
+
+	p.eatWhitespace()
+
+	return p.revive(el), nil
+}
+
+// expectComma consumes the ',' between members or elements.
+// In recovery mode a missing comma is recorded and parsing carries on
+// as if it were present.
+func (p *parser) expectComma() error {
+	if r, _ := p.r.peek(); r != ',' && p.cfg.recovery {
+		p.tryRecover(p.expectedError(",", r))
+		return nil
+	}
+
+	if r := p.r.read(); r != ',' {
+		return p.expectedError(",", r)
+	}
+	p.eatWhitespace()
+	return nil
+}
+
+// isTrailingComma reports whether the comma just consumed is followed
+// by the closing bracket and trailing commas are allowed.
+func (p *parser) isTrailingComma(closer rune) bool {
+	if !p.cfg.trailingCommas {
+		return false
+	}
+	r, _ := p.r.peek()
+	return r == closer
+}
+
+// closeContainer consumes the closing bracket of an object or array.
+// In recovery mode a missing bracket is recorded and left for the
+// enclosing container to handle.
+func (p *parser) closeContainer(closer rune) error {
+	if r, _ := p.r.peek(); r != closer && p.cfg.recovery {
+		p.tryRecover(p.expectedError(string(closer), r))
+		return nil
+	}
+
+	if r := p.r.read(); r != closer {
+		return p.expectedError(string(closer), r)
+	}
+	return nil
+}
+
+func (p *parser) parseString() (*Element, error) {
+	raw, err := p.parseRawString()
+	if err != nil {
+		return nil, err
+	}
+	return &Element{
+		kind:  StringKind,
+		value: raw,
+	}, nil
+}
+
+// parseSingleQuotedString parses the rest of a single-quoted string
+// into a string element.
+func (p *parser) parseSingleQuotedString() (*Element, error) {
+	s, err := p.parseSingleQuoted()
+	if err != nil {
+		return nil, err
+	}
+	return &Element{
+		kind:  StringKind,
+		value: escapeString(s),
+	}, nil
+}
+
+func (p *parser) parseRawString() ([]byte, error) {
+	if r, _ := p.r.peek(); r == '"' {
+		p.r.read()
+		return []byte{}, nil
+	}
+
+	start, pin := p.r.mark()
+	defer p.r.release(pin)
+
+	var escape, invalid, control bool
+	for !p.r.isEOF() {
+		if p.cfg.invalidUTF8 != UTF8PassThrough {
+			if r, size := p.r.peek(); r >= utf8.RuneSelf && size == 1 {
+				if p.cfg.invalidUTF8 == UTF8Reject {
+					return nil, p.stringError(p.syntaxError(fmt.Errorf("%w %#x", ErrInvalidUTF8, r)))
+				}
+				invalid = true
+			}
+		}
+
+		r := p.r.read()
+		if !escape && r == '"' {
+			break
+		}
+
+		if !escape && isSpecialCharacter(r) {
+			if p.cfg.controlChars {
+				control = true
+				continue
+			}
+			return nil, p.stringError(p.syntaxError(fmt.Errorf("unescaped special caharacter %q", r)))
+		}
+
+		if escape {
+			switch r {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				for range 4 {
+					if h := p.r.read(); !isHex(h) {
+						return nil, p.stringError(p.expectedError("hexadecimal digit", h))
+					}
+				}
+			default:
+				return nil, p.stringError(p.syntaxError(fmt.Errorf("invalid escape character %q", r)))
+			}
+		}
+
+		if !escape && r == '\\' {
+			escape = true
+		} else {
+			escape = false
+		}
+
+	}
+
+	if start == p.r.offset {
+		return nil, p.syntaxError(fmt.Errorf("expected: \", but 'eof'"))
+	}
+	raw := p.r.slice(start, p.r.offset-1)
+	if invalid {
+		raw = bytes.ToValidUTF8(raw, []byte(string(utf8.RuneError)))
+	}
+	if control {
+		raw = escapeControlChars(raw)
+	}
+	return raw, nil
+}
+
+func isSpecialCharacter(r rune) bool {
+	return r >= 0 && r <= 31
+}
+
+func isHex(r rune) bool {
+	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
+}
+
+func (p *parser) parseNumber(start rune) (*Element, error) {
+	if p.cfg.nonFiniteNumbers {
+		if r, _ := p.r.peek(); start == 'N' || start == 'I' || (start == '-' || start == '+') && r == 'I' {
+			return p.parseNonFinite(start)
+		}
+	}
+
+	if p.cfg.lenientNumbers {
+		return p.parseLenientNumber(start)
+	}
+
+	var sb strings.Builder
+	sb.WriteRune(start)
+
+	if err := p.parseInteger(start, &sb); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseFraction(&sb); err != nil {
+		return nil, err
+	}
+
+	if err := p.parseExponent(&sb); err != nil {
+		return nil, err
+	}
+
+	v, err := p.decodeNumber(sb.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Element{
+		kind:  NumberKind,
+		value: v,
+	}, nil
+}
+
+// parseLenientNumber parses a number that may have a leading '+',
+// leading zeros, or no digits before or after the decimal point,
+// and normalizes it to the strict grammar.
+func (p *parser) parseLenientNumber(start rune) (*Element, error) {
+	var (
+		sign       string
+		integer    strings.Builder
+		fraction   strings.Builder
+		isFraction bool
+	)
+
+	switch start {
+	case '-', '+':
+		if start == '-' {
+			sign = "-"
+		}
+		if r, _ := p.r.peek(); r == '.' {
+			p.r.read()
+			isFraction = true
+		} else if !isDigit(r) {
+			return nil, p.expectedError("digit", p.r.read())
+		}
+	case '.':
+		isFraction = true
+	default:
+		integer.WriteRune(start)
+	}
+
+	if !isFraction {
+		p.readDigits(&integer)
+		if r, _ := p.r.peek(); r == '.' {
+			p.r.read()
+			isFraction = true
+		}
+	}
+	if isFraction {
+		p.readDigits(&fraction)
+	}
+	if integer.Len() == 0 && fraction.Len() == 0 {
+		return nil, p.syntaxError(fmt.Errorf("expected: digit"))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	if i := strings.TrimLeft(integer.String(), "0"); i != "" {
+		sb.WriteString(i)
+	} else {
+		sb.WriteRune('0')
+	}
+	if fraction.Len() != 0 {
+		sb.WriteRune('.')
+		sb.WriteString(fraction.String())
+	}
+
+	if err := p.parseExponent(&sb); err != nil {
+		return nil, err
+	}
+
+	v, err := p.decodeNumber(sb.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Element{
+		kind:  NumberKind,
+		value: v,
+	}, nil
+}
+
+func (p *parser) readDigits(sb *strings.Builder) {
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		if !isDigit(r) {
+			break
+		}
+		sb.WriteRune(p.r.read())
+	}
+}
+
+func (p *parser) parseNonFinite(start rune) (*Element, error) {
+	raw, suffix := "Infinity", "nfinity"
+	switch start {
+	case 'N':
+		raw, suffix = "NaN", "aN"
+	case '-':
+		raw = "-Infinity"
+		p.r.read()
+	case '+':
+		p.r.read()
+	}
+
+	if ok, expected, got := p.match(suffix); !ok {
+		return nil, p.expectedError(string(expected), got)
+	}
+
+	v, err := p.decodeNumber(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Element{
+		kind:  NumberKind,
+		value: v,
+	}, nil
+}
+
+func (p *parser) parseInteger(start rune, sb *strings.Builder) error {
+	if start == '-' {
+		r := p.r.read()
+		if r == '0' {
+			sb.WriteRune(r)
+			return nil
+		}
+		if !isNaturalDigit(r) {
+			return p.expectedError("digit '1-9'", r)
+		}
+		sb.WriteRune(r)
+	}
+
+	if start != '0' {
+		for !p.r.isEOF() {
+			r, _ := p.r.peek()
+			if !isDigit(r) {
+				break
+			}
+			sb.WriteRune(p.r.read())
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) parseFraction(sb *strings.Builder) error {
+	r, _ := p.r.peek()
+	if r == '.' {
+		sb.WriteRune('.')
+		p.r.read()
+		var hasDigit bool
+		for !p.r.isEOF() {
+			r, _ := p.r.peek()
+			if !isDigit(r) {
+				break
+			}
+			hasDigit = true
+			sb.WriteRune(p.r.read())
+		}
+
+		if !hasDigit {
+			return p.syntaxError(fmt.Errorf("expected: digit after fraction '.'"))
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) parseExponent(sb *strings.Builder) error {
+	r, _ := p.r.peek()
+	if r == 'e' || r == 'E' {
+		sb.WriteRune(r)
+		p.r.read()
+		r, _ = p.r.peek()
+		if r == '+' || r == '-' {
+			p.r.read()
+			sb.WriteRune(r)
+		}
+
+		var hasDigit bool
+
+		for !p.r.isEOF() {
+			r, _ := p.r.peek()
+			if !isDigit(r) {
+				break
+			}
+			hasDigit = true
+			sb.WriteRune(p.r.read())
+		}
+
+		if !hasDigit {
+			return p.syntaxError(fmt.Errorf("expected: digit after exponent"))
+		}
+	}
+
+	return nil
+}
+
+// parseIdentifier parses the rest of an identifier starting with first.
+func (p *parser) parseIdentifier(first rune) string {
+	var sb strings.Builder
+	sb.WriteRune(first)
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		if !isIdentifierPart(r) {
+			break
+		}
+		sb.WriteRune(p.r.read())
+	}
+	return sb.String()
+}
+
+// isIdentifierStart reports whether r may start an ECMAScript identifier.
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r)
+}
+
+func isNaturalDigit(r rune) bool {
+	return r >= '1' && r <= '9'
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func (p *parser) parseBool(start rune) (*Element, error) {
+	switch start {
+	case 't':
+		ok, expected, got := p.match("rue")
+		if ok {
+			return &Element{
+				kind:  BooleanKind,
+				value: true,
+			}, nil
+		}
+
+		return nil, p.expectedError(string(expected), got)
+	case 'f':
+		ok, expected, got := p.match("alse")
+		if ok {
+			return &Element{
+				kind:  BooleanKind,
+				value: false,
+			}, nil
+		}
+
+		return nil, p.expectedError(string(expected), got)
+	default:
+		panic("unreachable")
+	}
+}
+
+func (p *parser) parseNull() (*Element, error) {
+	ok, expected, got := p.match("ull")
+	if ok {
+		return &Element{
+			kind: NullKind,
+		}, nil
+	}
+
+	return nil, p.expectedError(string(expected), got)
+}
+
+func (p *parser) checkContext() error {
+	if p.ctx == nil {
+		return nil
+	}
+	p.steps++
+	if p.steps%contextCheckInterval != 0 {
+		return nil
+	}
+	return p.ctx.Err()
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.cfg.maxDepth > 0 && p.depth > p.cfg.maxDepth {
+		return p.syntaxError(fmt.Errorf("%w (%d)", ErrMaxDepth, p.cfg.maxDepth))
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) eatWhitespace() {
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+
+		if r == '/' && p.cfg.comments && p.readComment() {
+			continue
+		}
+
+		if !isWhitespace(r) {
+			break
+		}
+
+		p.r.read()
+	}
+}
+
+// skipComment skips a // or /* */ comment and reports whether there was one.
+// An unterminated block comment extends to the end of the input.
+func (p *parser) skipComment() bool {
+	switch p.r.peekByte(1) {
+	case '/':
+		for !p.r.isEOF() {
+			if r, _ := p.r.peek(); r == '\n' {
+				break
+			}
+			p.r.read()
+		}
+	case '*':
+		p.r.read()
+		p.r.read()
+		for !p.r.isEOF() {
+			if p.r.read() == '*' && p.r.peekByte(0) == '/' {
+				p.r.read()
+				break
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func isWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *parser) match(s string) (bool, rune, rune) {
+	for _, ss := range s {
+		r := p.r.read()
+		if r != ss {
+			return false, ss, r
+		}
+	}
+
+	return true, 0, 0
+}
+
+// tryRecover records a syntax error in recovery mode and reports
+// whether parsing may continue.
+func (p *parser) tryRecover(err error) bool {
+	var se *SyntaxError
+	if !p.cfg.recovery || !errors.As(err, &se) || errors.Is(err, ErrMaxDepth) {
+		return false
+	}
+	p.errs = append(p.errs, *se)
+	return true
+}
+
+// sync skips input up to the next ',', '}' or ']' at the current nesting level.
+func (p *parser) sync() {
+	var depth int
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		switch r {
+		case '"':
+			p.r.read()
+			p.skipString()
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return
+			}
+		}
+		p.r.read()
+	}
+}
+
+// skipString skips the rest of a string up to the closing quote or end of line.
+func (p *parser) skipString() {
+	var escape bool
+	for !p.r.isEOF() {
+		r := p.r.read()
+		if !escape && r == '"' || r == '\n' {
+			return
+		}
+		escape = !escape && r == '\\'
+	}
+}
+
+// stringError skips the rest of a malformed string in recovery mode,
+// so that resynchronization does not start inside of it.
+func (p *parser) stringError(err error) error {
+	if p.cfg.recovery {
+		p.skipString()
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package jsonparser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ApplyPatch applies the operations of the JSON Patch (RFC 6902) patch
+// to doc in place. Operations are applied in order to a copy of doc, which
+// replaces doc only if all of them succeed, so a failing operation such
+// as a failed test leaves doc unchanged.
+func ApplyPatch(doc, patch *Element) error {
+	ops, ok := patch.Array()
+	if !ok {
+		return fmt.Errorf("patch must be an array, got %s", patch.Kind())
+	}
+	result := doc.Clone()
+	for i, op := range ops {
+		if err := applyOperation(result, op); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	doc.ReplaceValue(result)
+	return nil
+}
+
+func applyOperation(doc, op *Element) error {
+	name, err := operationString(op, "op")
+	if err != nil {
+		return err
+	}
+	path, err := operationString(op, "path")
+	if err != nil {
+		return err
+	}
+	tokens, err := splitPointer(path)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "add":
+		value, err := operationMember(op, "value")
+		if err != nil {
+			return err
+		}
+		return patchAdd(doc, tokens, value.Clone())
+	case "remove":
+		_, err := patchRemove(doc, tokens)
+		return err
+	case "replace":
+		value, err := operationMember(op, "value")
+		if err != nil {
+			return err
+		}
+		target, err := resolveTokens(doc, tokens)
+		if err != nil {
+			return err
+		}
+		target.ReplaceValue(value.Clone())
+		return nil
+	case "move", "copy":
+		from, err := operationString(op, "from")
+		if err != nil {
+			return err
+		}
+		fromTokens, err := splitPointer(from)
+		if err != nil {
+			return err
+		}
+		if name == "copy" {
+			value, err := resolveTokens(doc, fromTokens)
+			if err != nil {
+				return err
+			}
+			return patchAdd(doc, tokens, value.Clone())
+		}
+		switch {
+		case slices.Equal(fromTokens, tokens):
+			return nil
+		case len(fromTokens) < len(tokens) && slices.Equal(fromTokens, tokens[:len(fromTokens)]):
+			return fmt.Errorf("cannot move %q into itself", from)
+		}
+		value, err := patchRemove(doc, fromTokens)
+		if err != nil {
+			return err
+		}
+		return patchAdd(doc, tokens, value)
+	case "test":
+		value, err := operationMember(op, "value")
+		if err != nil {
+			return err
+		}
+		target, err := resolveTokens(doc, tokens)
+		if err != nil {
+			return err
+		}
+		if !Equal(target, value) {
+			return fmt.Errorf("test failed: %s is not %s", path, Minify(value))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported op %q", name)
+	}
+}
+
+// patchAdd adds value at the location referenced by tokens,
+// replacing an existing member or inserting into an array.
+func patchAdd(doc *Element, tokens []string, value *Element) error {
+	if len(tokens) == 0 {
+		doc.ReplaceValue(value)
+		return nil
+	}
+	parent, err := resolveTokens(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+
+	last := tokens[len(tokens)-1]
+	parent.load()
+	switch parent.kind {
+	case ObjectKind:
+		return parent.SetMember(last, value)
+	case ArrayKind:
+		if last == "-" {
+			return parent.Append(value)
+		}
+		idx, err := arrayIndex(last)
+		if err != nil {
+			return err
+		}
+		return parent.InsertAt(idx, value)
+	default:
+		return fmt.Errorf("cannot add to %s", parent.kind)
+	}
+}
+
+// patchRemove removes the member or array element referenced by tokens
+// and returns it.
+func patchRemove(doc *Element, tokens []string) (*Element, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the document")
+	}
+	parent, err := resolveTokens(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	// the target must exist
+	last := tokens[len(tokens)-1]
+	value, err := child(parent, last)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q: %w", joinPointer(tokens), err)
+	}
+
+	if parent.kind == ObjectKind {
+		return value, parent.RemoveMember(last)
+	}
+	idx, _ := arrayIndex(last)
+	return value, parent.RemoveAt(idx)
+}
+
+// operationMember returns the value of the member key of a patch operation.
+func operationMember(op *Element, key string) (*Element, error) {
+	members, ok := op.Object()
+	if !ok {
+		return nil, fmt.Errorf("operation must be an object, got %s", op.Kind())
+	}
+	for _, m := range members {
+		if m.Key() == key {
+			return m.value, nil
+		}
+	}
+	return nil, fmt.Errorf("missing %q", key)
+}
+
+func operationString(op *Element, key string) (string, error) {
+	el, err := operationMember(op, key)
+	if err != nil {
+		return "", err
+	}
+	s, ok := el.Str()
+	if !ok {
+		return "", fmt.Errorf("%q must be a string, got %s", key, el.Kind())
+	}
+	return s, nil
+}
@@ -0,0 +1,204 @@
+package jsonparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResolvePointer returns the element referenced by the JSON Pointer ptr (RFC 6901).
+func ResolvePointer(root *Element, ptr string) (*Element, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return resolveTokens(root, tokens)
+}
+
+// ResolveRelativePointer evaluates the Relative JSON Pointer rel
+// (draft-bhutton-relative-json-pointer) starting from the element
+// referenced by the JSON Pointer from.
+//
+// A pointer ending with "#" yields the member name (as a string element)
+// or the array index (as a number element) of the referenced element.
+func ResolveRelativePointer(root *Element, from, rel string) (*Element, error) {
+	tokens, err := splitPointer(from)
+	if err != nil {
+		return nil, err
+	}
+
+	up, rest, err := parsePrefix(rel)
+	if err != nil {
+		return nil, err
+	}
+	if up > len(tokens) {
+		return nil, fmt.Errorf("relative pointer %q: cannot go up %d levels from %q", rel, up, from)
+	}
+	tokens = tokens[:len(tokens)-up]
+
+	if rest != "" && (rest[0] == '+' || rest[0] == '-') {
+		var delta int
+		delta, rest, err = parseIndexManipulation(rest)
+		if err != nil {
+			return nil, fmt.Errorf("relative pointer %q: %w", rel, err)
+		}
+		if tokens, err = shiftIndex(root, tokens, delta); err != nil {
+			return nil, fmt.Errorf("relative pointer %q: %w", rel, err)
+		}
+	}
+
+	if rest == "#" {
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("relative pointer %q: root has no name or index", rel)
+		}
+		parent, err := resolveTokens(root, tokens[:len(tokens)-1])
+		if err != nil {
+			return nil, err
+		}
+		last := tokens[len(tokens)-1]
+		if parent.kind == ArrayKind {
+			return &Element{kind: NumberKind, value: last}, nil
+		}
+		return &Element{kind: StringKind, value: escapeString(last)}, nil
+	}
+
+	more, err := splitPointer(rest)
+	if err != nil {
+		return nil, fmt.Errorf("relative pointer %q: %w", rel, err)
+	}
+	return resolveTokens(root, append(tokens, more...))
+}
+
+func parsePrefix(rel string) (int, string, error) {
+	i := 0
+	for i < len(rel) && isDigit(rune(rel[i])) {
+		i++
+	}
+	if i == 0 || i > 1 && rel[0] == '0' {
+		return 0, "", fmt.Errorf("relative pointer %q: expected non-negative integer prefix", rel)
+	}
+	n, err := strconv.Atoi(rel[:i])
+	if err != nil {
+		return 0, "", fmt.Errorf("relative pointer %q: %w", rel, err)
+	}
+	return n, rel[i:], nil
+}
+
+func parseIndexManipulation(s string) (int, string, error) {
+	i := 1
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
+	}
+	if i == 1 || i > 2 && s[1] == '0' {
+		return 0, "", fmt.Errorf("invalid index manipulation %q", s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, "", err
+	}
+	return n, s[i:], nil
+}
+
+func shiftIndex(root *Element, tokens []string, delta int) ([]string, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("index manipulation on root")
+	}
+	parent, err := resolveTokens(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	if parent.kind != ArrayKind {
+		return nil, fmt.Errorf("index manipulation on a member of %s", parent.kind)
+	}
+	idx, _ := strconv.Atoi(tokens[len(tokens)-1])
+	idx += delta
+	if idx < 0 {
+		return nil, fmt.Errorf("index %d out of range", idx)
+	}
+	tokens = append(tokens[:len(tokens)-1:len(tokens)-1], strconv.Itoa(idx))
+	return tokens, nil
+}
+
+// splitPointer splits a JSON Pointer into unescaped reference tokens.
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with '/'", ptr)
+	}
+
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		for j := 0; j < len(t); j++ {
+			if t[j] == '~' && (j+1 == len(t) || t[j+1] != '0' && t[j+1] != '1') {
+				return nil, fmt.Errorf("invalid JSON pointer %q: bad escape sequence", ptr)
+			}
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// escapePointerToken escapes a reference token for use in a JSON Pointer.
+func escapePointerToken(t string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(t)
+}
+
+func resolveTokens(root *Element, tokens []string) (*Element, error) {
+	el := root
+	for i, t := range tokens {
+		next, err := child(el, t)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve %q: %w", joinPointer(tokens[:i+1]), err)
+		}
+		el = next
+	}
+	return el, nil
+}
+
+func child(el *Element, token string) (*Element, error) {
+	el.load()
+	switch el.kind {
+	case ObjectKind:
+		for _, m := range el.value.([]Member) {
+			if unescape(m.key) == token {
+				return m.value, nil
+			}
+		}
+		return nil, fmt.Errorf("member %q not found", token)
+	case ArrayKind:
+		elements := el.value.([]*Element)
+		idx, err := arrayIndex(token)
+		if err != nil {
+			return nil, err
+		}
+		if idx >= len(elements) {
+			return nil, fmt.Errorf("index %d out of range", idx)
+		}
+		return elements[idx], nil
+	default:
+		return nil, fmt.Errorf("%s has no children", el.kind)
+	}
+}
+
+func arrayIndex(token string) (int, error) {
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	for _, r := range token {
+		if !isDigit(r) {
+			return 0, fmt.Errorf("invalid array index %q", token)
+		}
+	}
+	return strconv.Atoi(token)
+}
+
+func joinPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteRune('/')
+		sb.WriteString(escapePointerToken(t))
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package jsonparser
+
+// Profile is a named bundle of parse options.
+type Profile uint8
+
+const (
+	// ProfileStrict follows RFC 8259 to the letter: duplicate keys
+	// and invalid UTF-8 are rejected.
+	ProfileStrict Profile = iota
+	// ProfileLenient accepts common deviations from the standard:
+	// comments, trailing commas, NaN, Infinity, relaxed numbers and raw
+	// control characters in strings. Invalid UTF-8 is replaced and
+	// the last of duplicate keys wins.
+	ProfileLenient
+	// ProfileJSON5 accepts the JSON5 extensions the parser supports.
+	ProfileJSON5
+)
+
+// WithProfile applies the options bundled by the profile.
+// Options given after it override the profile's settings.
+func WithProfile(p Profile) Option {
+	var opts []Option
+	switch p {
+	case ProfileStrict:
+		opts = []Option{
+			WithDuplicateKeyPolicy(DuplicateKeysReject),
+			WithInvalidUTF8(UTF8Reject),
+		}
+	case ProfileLenient:
+		opts = []Option{
+			WithComments(),
+			WithAllowTrailingCommas(),
+			WithNonFiniteNumbers(),
+			WithAllowControlChars(),
+			WithLenientNumbers(),
+			WithInvalidUTF8(UTF8Replace),
+			WithDuplicateKeyPolicy(DuplicateKeysLastWins),
+		}
+	case ProfileJSON5:
+		opts = []Option{
+			WithComments(),
+			WithAllowTrailingCommas(),
+			WithNonFiniteNumbers(),
+			WithSingleQuotes(),
+			WithUnquotedKeys(),
+			WithLenientNumbers(),
+		}
+	}
+
+	return func(c *config) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
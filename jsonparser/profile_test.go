@@ -0,0 +1,61 @@
+package jsonparser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "strict accepts standard JSON", profile: ProfileStrict, input: `{"a": [1, 2.5, "x"]}`, want: `{"a":[1,2.5,"x"]}`},
+		{name: "strict rejects duplicate keys", profile: ProfileStrict, input: `{"a": 1, "a": 2}`, wantErr: true},
+		{name: "strict rejects invalid UTF-8", profile: ProfileStrict, input: "\"\xff\"", wantErr: true},
+		{name: "strict rejects comments", profile: ProfileStrict, input: `[1 /* one */]`, wantErr: true},
+		{name: "lenient keeps the last duplicate key", profile: ProfileLenient, input: `{"a": 1, "a": 2}`, want: `{"a":2}`},
+		{name: "lenient accepts comments and trailing commas", profile: ProfileLenient, input: "[1, // one\n2,]", want: `[1,2]`},
+		{name: "lenient accepts relaxed numbers", profile: ProfileLenient, input: `[007, +3, .5, 5.]`, want: `[7,3,0.5,5]`},
+		{name: "lenient accepts non-finite numbers", profile: ProfileLenient, input: `[NaN, Infinity, -Infinity]`, want: `[NaN,Infinity,-Infinity]`},
+		{name: "lenient rejects single quotes", profile: ProfileLenient, input: `['a']`, wantErr: true},
+		{name: "json5 accepts unquoted keys and single quotes", profile: ProfileJSON5, input: `{a: 'x', b_1: "y",}`, want: `{"a":"x","b_1":"y"}`},
+		{name: "json5 accepts relaxed numbers", profile: ProfileJSON5, input: `[+1, .5, 5.]`, want: `[1,0.5,5]`},
+		{name: "json5 accepts non-finite numbers", profile: ProfileJSON5, input: `[NaN, Infinity, -Infinity]`, want: `[NaN,Infinity,-Infinity]`},
+		{name: "json5 accepts +Infinity", profile: ProfileJSON5, input: `{a: +Infinity}`, want: `{"a":Infinity}`},
+		{name: "json5 rejects a sign without digits", profile: ProfileJSON5, input: `[+]`, wantErr: true},
+		{name: "json5 rejects +Inf", profile: ProfileJSON5, input: `[+Inf]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseString(tt.input, WithProfile(tt.profile))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", Minify(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := Minify(got); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithProfileLexer(t *testing.T) {
+	got, err := lex(`[+Infinity, -Infinity]`, WithProfile(ProfileJSON5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TokenKind{ArrayStartToken, NumberToken, CommaToken, NumberToken, ArrayEndToken}
+	if !slices.Equal(got, want) {
+		t.Errorf("got tokens %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package jsonparser
+
+// Pick returns a copy of the document el keeping only the members at
+// the given paths and the objects leading to them. Paths are dotted
+// member names, such as "name" or "address.city", whose components
+// may use the wildcards of Element.Get, and a "**" component matches
+// any number of levels, so that "**.id" selects the id members at any
+// depth. Arrays are projected element by element, keeping the elements
+// with a selected member. The values are not copied, and scalar
+// documents are returned as they are.
+func Pick(el *Element, keys ...string) *Element {
+	v, _ := project(el, projectionPaths(keys), true)
+	return v
+}
+
+// Omit returns a copy of the document el without the members at the
+// given paths, which are written as for Pick, so that "**.password"
+// removes the password members at any depth. Arrays are projected
+// element by element. The values are not copied, and scalar documents
+// are returned as they are.
+func Omit(el *Element, keys ...string) *Element {
+	v, _ := project(el, projectionPaths(keys), false)
+	return v
+}
+
+func projectionPaths(keys []string) [][]string {
+	var paths [][]string
+	for _, k := range keys {
+		if parts := splitGetPath(k); len(parts) > 0 {
+			paths = append(paths, parts)
+		}
+	}
+	return paths
+}
+
+// project returns el with the members at the paths kept if pick is set
+// or removed otherwise, and whether a member of el was selected.
+func project(el *Element, paths [][]string, pick bool) (*Element, bool) {
+	var found bool
+	if members, ok := el.Object(); ok {
+		kept := []Member{}
+		for _, m := range members {
+			full, next := advancePaths(paths, m.Key())
+			switch {
+			case full:
+				found = true
+				if pick {
+					kept = append(kept, m)
+				}
+			case len(next) > 0:
+				v, ok := project(m.value, next, pick)
+				found = found || ok
+				if ok || !pick {
+					m.value = v
+					kept = append(kept, m)
+				}
+			case !pick:
+				kept = append(kept, m)
+			}
+		}
+		return &Element{kind: ObjectKind, value: kept, span: el.span, comments: el.comments}, found
+	}
+	if elements, ok := el.Array(); ok {
+		kept := []*Element{}
+		for _, e := range elements {
+			v, ok := project(e, paths, pick)
+			found = found || ok
+			if ok || !pick {
+				kept = append(kept, v)
+			}
+		}
+		return &Element{kind: ArrayKind, value: kept, span: el.span, comments: el.comments}, found
+	}
+	return el, false
+}
+
+// advancePaths matches the first components of paths against the key of
+// a member, reporting whether a path ends at the member and returning
+// the rest of the paths continuing below it.
+func advancePaths(paths [][]string, key string) (full bool, next [][]string) {
+	for _, p := range paths {
+		if p[0] == "**" {
+			// "**" continues below the member, or matches no level
+			next = append(next, p)
+			if len(p) == 1 {
+				full = true
+				continue
+			}
+			f, n := advancePaths([][]string{p[1:]}, key)
+			full = full || f
+			next = append(next, n...)
+			continue
+		}
+		if matchKey(p[0], key) {
+			if len(p) == 1 {
+				full = true
+			} else {
+				next = append(next, p[1:])
+			}
+		}
+	}
+	return full, next
+}
@@ -0,0 +1,66 @@
+package jsonparser
+
+import "testing"
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		keys  []string
+		want  string
+	}{
+		{name: "top-level members", input: `{"a": 1, "b": 2, "c": 3}`, keys: []string{"a", "c"}, want: `{"a":1,"c":3}`},
+		{name: "nested member", input: `{"a": {"b": 1, "c": 2}, "d": 3}`, keys: []string{"a.b"}, want: `{"a":{"b":1}}`},
+		{name: "no match", input: `{"a": 1}`, keys: []string{"x"}, want: `{}`},
+		{name: "array elements", input: `[{"id": 1, "x": 1}, {"id": 2, "y": 2}]`, keys: []string{"id"}, want: `[{"id":1},{"id":2}]`},
+		{name: "array elements without the member are dropped", input: `[{"id": 1}, {"x": 2}, 3, [{"id": 4}]]`, keys: []string{"id"}, want: `[{"id":1},[{"id":4}]]`},
+		{name: "array without matches", input: `{"a": [1, {"x": 2}], "id": 3}`, keys: []string{"a.id", "id"}, want: `{"id":3}`},
+		{name: "array below a member", input: `{"items": [{"id": 1, "x": 1}, {"id": 2}], "n": 2}`, keys: []string{"items.id"}, want: `{"items":[{"id":1},{"id":2}]}`},
+		{name: "whole array", input: `{"items": [1, 2], "n": 2}`, keys: []string{"items"}, want: `{"items":[1,2]}`},
+		{name: "any depth", input: `{"id": 1, "a": [{"id": 2, "b": {"id": 3, "c": 4}}]}`, keys: []string{"**.id"}, want: `{"id":1,"a":[{"id":2,"b":{"id":3}}]}`},
+		{name: "wildcard", input: `{"a": {"x": 1, "y": 2}, "b": {"x": 3}}`, keys: []string{"*.x"}, want: `{"a":{"x":1},"b":{"x":3}}`},
+		{name: "scalar document", input: `42`, keys: []string{"a"}, want: `42`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Minify(Pick(doc, tt.keys...)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOmit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		keys  []string
+		want  string
+	}{
+		{name: "top-level members", input: `{"a": 1, "b": 2, "c": 3}`, keys: []string{"a", "c"}, want: `{"b":2}`},
+		{name: "nested member", input: `{"a": {"b": 1, "c": 2}, "d": 3}`, keys: []string{"a.b"}, want: `{"a":{"c":2},"d":3}`},
+		{name: "no match", input: `{"a": 1}`, keys: []string{"x"}, want: `{"a":1}`},
+		{name: "array elements are kept", input: `[{"id": 1, "x": 1}, {"y": 2}, 3, [{"id": 4}]]`, keys: []string{"id"}, want: `[{"x":1},{"y":2},3,[{}]]`},
+		{name: "array below a member", input: `{"items": [{"id": 1, "x": 1}, {"id": 2}], "n": 2}`, keys: []string{"items.id"}, want: `{"items":[{"x":1},{}],"n":2}`},
+		{name: "whole array", input: `{"items": [1, 2], "n": 2}`, keys: []string{"items"}, want: `{"n":2}`},
+		{name: "any depth", input: `{"password": 1, "a": [{"password": 2, "b": {"password": 3, "c": 4}}]}`, keys: []string{"**.password"}, want: `{"a":[{"b":{"c":4}}]}`},
+		{name: "scalar document", input: `42`, keys: []string{"a"}, want: `42`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Minify(Omit(doc, tt.keys...)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
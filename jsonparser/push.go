@@ -0,0 +1,57 @@
+package jsonparser
+
+import (
+	"errors"
+	"io"
+)
+
+// PushParser parses a JSON document handed to it in chunks, for callers
+// that receive input piecemeal, such as from a socket or a chunked HTTP body.
+// The document is never buffered as a whole.
+//
+// Finish must be called to release the resources held by the parser.
+type PushParser struct {
+	w      *io.PipeWriter
+	done   chan struct{}
+	el     *Element
+	err    error
+	closed bool
+}
+
+// NewPushParser returns a parser fed with Feed.
+func NewPushParser(opts ...Option) *PushParser {
+	r, w := io.Pipe()
+	pp := &PushParser{w: w, done: make(chan struct{})}
+
+	go func() {
+		defer close(pp.done)
+		pp.el, pp.err = newStreamParser(r, opts...).parse()
+		// unblock a pending Feed with the error parsing failed with
+		r.CloseWithError(pp.err)
+	}()
+
+	return pp
+}
+
+// Feed passes the next chunk of the document to the parser.
+// It returns once the chunk is consumed, or the error parsing failed with.
+func (pp *PushParser) Feed(chunk []byte) error {
+	if pp.closed {
+		return errors.New("feed after finish")
+	}
+	if len(chunk) == 0 {
+		return nil
+	}
+	_, err := pp.w.Write(chunk)
+	return err
+}
+
+// Finish signals the end of the document and returns the parsed element.
+func (pp *PushParser) Finish() (*Element, error) {
+	if !pp.closed {
+		pp.closed = true
+		pp.w.Close()
+	}
+	<-pp.done
+	return pp.el, pp.err
+}
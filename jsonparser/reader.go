@@ -0,0 +1,139 @@
+package jsonparser
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+const readerChunkSize = 4096
+
+type reader struct {
+	// src is an optional source the buffer is refilled from.
+	src io.Reader
+	err error
+	// limit is the maximum number of bytes read from src, 0 if unlimited.
+	limit int64
+	// s holds the buffered input, s[0] is at the absolute offset base.
+	s    []byte
+	base int
+	// pin is the absolute offset from which input is retained while
+	// refilling the buffer, -1 if nothing is pinned.
+	pin int
+
+	line   int
+	col    int
+	offset int
+	// columns defines how col advances.
+	columns columns
+	// last is the position of the rune returned by read,
+	// valid if consumed is set, that is until the next peek.
+	last     Position
+	consumed bool
+}
+
+// fill makes sure a whole rune is buffered, reading from src if needed.
+func (r *reader) fill() {
+	for r.src != nil && r.err == nil && len(r.s)-(r.offset-r.base) < utf8.UTFMax {
+		if len(r.s) == cap(r.s) {
+			keep := r.offset
+			if r.pin >= 0 && r.pin < keep {
+				keep = r.pin
+			}
+			// Previously returned slices may still reference the old buffer,
+			// so the retained tail is always copied into a fresh one.
+			tail := r.s[keep-r.base:]
+			buf := make([]byte, len(tail), len(tail)+readerChunkSize)
+			copy(buf, tail)
+			r.s, r.base = buf, keep
+		}
+
+		n, err := r.src.Read(r.s[len(r.s):cap(r.s)])
+		r.s = r.s[:len(r.s)+n]
+		if err != nil {
+			r.err = err
+		} else if r.limit > 0 && int64(r.base+len(r.s)) > r.limit {
+			r.err = inputTooLarge(r.limit)
+		}
+	}
+}
+
+// mark returns the current offset and retains the input from it
+// until release is called with the returned pin, so that it can be
+// sliced later. Marks may be nested.
+func (r *reader) mark() (offset, pin int) {
+	pin = r.pin
+	if r.pin < 0 {
+		r.pin = r.offset
+	}
+	return r.offset, pin
+}
+
+func (r *reader) release(pin int) {
+	r.pin = pin
+}
+
+func (r *reader) slice(start, end int) []byte {
+	return r.s[start-r.base : end-r.base]
+}
+
+func (r *reader) pos() Position {
+	return Position{Offset: r.offset, Line: r.line, Col: r.col + 1}
+}
+
+// errorPos returns the position syntax errors are reported at:
+// the rune just read, which is the offending one unless the parser
+// looked past it, and the next rune or the end of the input otherwise.
+func (r *reader) errorPos() Position {
+	if r.consumed {
+		return r.last
+	}
+	return r.pos()
+}
+
+func (r *reader) isEOF() bool {
+	r.fill()
+	return r.offset-r.base >= len(r.s)
+}
+
+func (r *reader) peek() (v rune, size int) {
+	r.consumed = false
+	if r.isEOF() {
+		return v, 0
+	}
+	i := r.offset - r.base
+	v, size = rune(r.s[i]), 1
+	// check if rune is base ASCII character
+	if v >= 128 {
+		v, size = utf8.DecodeRune(r.s[i:])
+		if v == utf8.RuneError && size == 1 {
+			v = rune(r.s[i]) // illegal encoding
+		}
+	}
+	return v, size
+}
+
+// peekByte returns the byte i positions ahead of the current offset,
+// or 0 past the end of the input. i must be less than utf8.UTFMax.
+func (r *reader) peekByte(i int) byte {
+	r.fill()
+	if j := r.offset - r.base + i; j < len(r.s) {
+		return r.s[j]
+	}
+	return 0
+}
+
+func (r *reader) read() (v rune) {
+	v, s := r.peek()
+	if r.isEOF() {
+		return v
+	}
+	r.last, r.consumed = r.pos(), true
+	if v == '\n' {
+		r.col = 0
+		r.line++
+	} else {
+		r.col = r.columns.advance(r.col, v, s)
+	}
+	r.offset += s
+	return v
+}
@@ -0,0 +1,122 @@
+package jsonparser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RedactMode is how Redact replaces values.
+type RedactMode uint8
+
+const (
+	// RedactMask replaces values with the string "***".
+	RedactMask RedactMode = iota
+	// RedactHash replaces values with a string like "sha256:1f2e…" of the
+	// first 16 hex digits of the SHA-256 hash of their canonical form,
+	// so that equal values can still be correlated. Hashes of values
+	// with few possibilities, such as short numbers, can be reversed
+	// by trying them all.
+	RedactHash
+)
+
+// RedactRule selects the values Redact replaces.
+type RedactRule struct {
+	// Pattern is a JSON Pointer selecting a single value if it starts
+	// with "/". Otherwise it is a dotted path of member names, or
+	// array indices, selecting the values whose paths end with it,
+	// such as the members named password anywhere for "password", and
+	// the token members of any member for "*.token". In the components,
+	// "*" matches any characters, "?" a single one, and "\" escapes
+	// the next character, such as a dot.
+	Pattern string
+	Mode    RedactMode
+}
+
+// Redact replaces the values of the document el selected by the rules
+// in place, applying the first matching rule to each value. It returns
+// an error for empty patterns and invalid JSON Pointers.
+func Redact(el *Element, rules []RedactRule) error {
+	type matcher struct {
+		pointer []string
+		parts   []string
+		mode    RedactMode
+	}
+	matchers := make([]matcher, len(rules))
+	for i, r := range rules {
+		matchers[i].mode = r.Mode
+		if r.Pattern == "" {
+			return errors.New("empty redaction pattern")
+		}
+		if strings.HasPrefix(r.Pattern, "/") {
+			tokens, err := splitPointer(r.Pattern)
+			if err != nil {
+				return err
+			}
+			matchers[i].pointer = tokens
+		} else {
+			matchers[i].parts = splitGetPath(r.Pattern)
+		}
+	}
+
+	var walk func(el *Element, path []string)
+	walk = func(el *Element, path []string) {
+		for _, m := range matchers {
+			var ok bool
+			if m.pointer != nil {
+				ok = slices.Equal(m.pointer, path)
+			} else {
+				ok = len(path) > 0 && matchPathSuffix(m.parts, path)
+			}
+			if ok {
+				el.ReplaceValue(redacted(el, m.mode))
+				return
+			}
+		}
+		// the paths of the children share the array of tokens
+		path = path[:len(path):len(path)]
+		if members, ok := el.Object(); ok {
+			for _, m := range members {
+				walk(m.value, append(path, m.Key()))
+			}
+		} else if elements, ok := el.Array(); ok {
+			for i, e := range elements {
+				walk(e, append(path, strconv.Itoa(i)))
+			}
+		}
+	}
+	walk(el, []string{})
+	return nil
+}
+
+// matchPathSuffix reports whether the last components of path
+// match the patterns of parts.
+func matchPathSuffix(parts, path []string) bool {
+	if len(parts) > len(path) {
+		return false
+	}
+	path = path[len(path)-len(parts):]
+	for i, p := range parts {
+		if !matchKey(p, path[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// redacted returns the replacement of el.
+func redacted(el *Element, mode RedactMode) *Element {
+	if mode != RedactHash {
+		return &Element{kind: StringKind, value: []byte("***")}
+	}
+	text, err := Canonical(el)
+	if err != nil {
+		// numbers out of the range of float64
+		text = Minify(el)
+	}
+	sum := sha256.Sum256([]byte(text))
+	return &Element{kind: StringKind, value: []byte("sha256:" + hex.EncodeToString(sum[:8]))}
+}
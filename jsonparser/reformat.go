@@ -0,0 +1,137 @@
+package jsonparser
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// MinifyStream reads a single JSON document from r and writes it to w
+// without insignificant whitespace, like Minify. The document is
+// reformatted as it is read, without building an element tree, so memory
+// use does not grow with its size. The restrictions of ParseWithHandler
+// apply, and comments are dropped.
+//
+// On a syntax error, the output written so far is incomplete.
+func MinifyStream(w io.Writer, r io.Reader, opts ...Option) error {
+	return reformat(w, r, nil, opts)
+}
+
+// PrettyStream reads a single JSON document from r and writes it to w
+// laid out according to indent, like PrettyIndent. As with MinifyStream,
+// no element tree is built.
+func PrettyStream(w io.Writer, r io.Reader, indent IndentOptions, opts ...Option) error {
+	return reformat(w, r, &indent, opts)
+}
+
+func reformat(w io.Writer, r io.Reader, indent *IndentOptions, opts []Option) error {
+	f := &reformatter{w: bufio.NewWriter(w), indent: indent}
+	if err := ParseWithHandler(r, f, opts...); err != nil {
+		f.w.Flush()
+		return err
+	}
+	return f.w.Flush()
+}
+
+// reformatter is a Handler writing the events it receives.
+type reformatter struct {
+	w      *bufio.Writer
+	indent *IndentOptions
+	// counts holds the number of members or elements
+	// written so far into each open container.
+	counts []int
+	// afterKey is set once a key was written,
+	// so that its value continues the line.
+	afterKey bool
+}
+
+// value starts a key or a value, writing the separator
+// and the line break preceding it.
+func (f *reformatter) value() {
+	if f.afterKey {
+		f.afterKey = false
+		return
+	}
+	if len(f.counts) == 0 {
+		return
+	}
+
+	n := &f.counts[len(f.counts)-1]
+	if *n != 0 {
+		f.w.WriteByte(',')
+	}
+	*n++
+	f.newline(len(f.counts))
+}
+
+func (f *reformatter) newline(lvl int) {
+	if f.indent == nil {
+		return
+	}
+	f.w.WriteByte('\n')
+	f.w.WriteString(f.indent.Prefix)
+	f.w.WriteString(strings.Repeat(f.indent.Indent, lvl))
+}
+
+func (f *reformatter) start(open byte) error {
+	f.value()
+	f.counts = append(f.counts, 0)
+	return f.w.WriteByte(open)
+}
+
+func (f *reformatter) end(close byte) error {
+	n := f.counts[len(f.counts)-1]
+	f.counts = f.counts[:len(f.counts)-1]
+	if n != 0 {
+		f.newline(len(f.counts))
+	}
+	return f.w.WriteByte(close)
+}
+
+func (f *reformatter) scalar(s string) error {
+	f.value()
+	_, err := f.w.WriteString(s)
+	return err
+}
+
+func (f *reformatter) OnObjectStart() error    { return f.start('{') }
+func (f *reformatter) OnObjectEnd() error      { return f.end('}') }
+func (f *reformatter) OnArrayStart() error     { return f.start('[') }
+func (f *reformatter) OnArrayEnd() error       { return f.end(']') }
+func (f *reformatter) OnNumber(n string) error { return f.scalar(n) }
+func (f *reformatter) OnNull() error           { return f.scalar("null") }
+
+func (f *reformatter) OnBool(b bool) error {
+	if b {
+		return f.scalar("true")
+	}
+	return f.scalar("false")
+}
+
+func (f *reformatter) OnKey(key string) error {
+	return f.onRawKey(escapeString(key))
+}
+
+func (f *reformatter) OnString(s string) error {
+	return f.onRawString(escapeString(s))
+}
+
+func (f *reformatter) onRawKey(raw []byte) error {
+	f.value()
+	f.w.WriteByte('"')
+	f.w.Write(raw)
+	f.w.WriteByte('"')
+	f.afterKey = true
+	if f.indent != nil {
+		_, err := f.w.WriteString(": ")
+		return err
+	}
+	return f.w.WriteByte(':')
+}
+
+func (f *reformatter) onRawString(raw []byte) error {
+	f.value()
+	f.w.WriteByte('"')
+	f.w.Write(raw)
+	return f.w.WriteByte('"')
+}
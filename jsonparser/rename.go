@@ -0,0 +1,133 @@
+package jsonparser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyCase is a naming convention of object keys.
+type KeyCase uint8
+
+const (
+	// CamelCase joins capitalized words after a lowercase one, as in "userId".
+	CamelCase KeyCase = iota + 1
+	// PascalCase joins capitalized words, as in "UserId".
+	PascalCase
+	// SnakeCase joins lowercase words with underscores, as in "user_id".
+	SnakeCase
+	// KebabCase joins lowercase words with hyphens, as in "user-id".
+	KebabCase
+)
+
+func (c KeyCase) String() string {
+	switch c {
+	case CamelCase:
+		return "camel"
+	case PascalCase:
+		return "pascal"
+	case SnakeCase:
+		return "snake"
+	case KebabCase:
+		return "kebab"
+	default:
+		return "unknown"
+	}
+}
+
+// RenameKeys renames the members of the objects in the document el in
+// place to the names rename returns for their keys. Keys rename returns
+// unchanged keep their escaping, and renaming several members of an
+// object to the same name leaves duplicate keys.
+func RenameKeys(el *Element, rename func(key string) string) {
+	if members, ok := el.Object(); ok {
+		for i, m := range members {
+			key := m.Key()
+			if name := rename(key); name != key {
+				members[i].key = escapeString(name)
+			}
+			RenameKeys(m.value, rename)
+		}
+	} else if elements, ok := el.Array(); ok {
+		for _, e := range elements {
+			RenameKeys(e, rename)
+		}
+	}
+}
+
+// ConvertKeyCase renames the keys of the objects in the document el
+// in place to the naming convention c, as ConvertKey.
+func ConvertKeyCase(el *Element, c KeyCase) {
+	RenameKeys(el, func(key string) string { return ConvertKey(key, c) })
+}
+
+// ConvertKey returns key in the naming convention c. Keys are split
+// into words at underscores, hyphens, spaces and dots, before an upper
+// case letter following a lower case letter or a digit, and before
+// the last letter of a run of upper case letters followed by a lower
+// case one, so that "HTTPServer" has the words "HTTP" and "Server".
+// Leading underscores are kept, as in "_id".
+func ConvertKey(key string, c KeyCase) string {
+	trimmed := strings.TrimLeft(key, "_")
+	words := keyWords(trimmed)
+	if len(words) == 0 {
+		return key
+	}
+
+	var sb strings.Builder
+	sb.WriteString(key[:len(key)-len(trimmed)])
+	for i, w := range words {
+		w = strings.ToLower(w)
+		switch c {
+		case CamelCase, PascalCase:
+			if i > 0 || c == PascalCase {
+				r, size := utf8.DecodeRuneInString(w)
+				w = string(unicode.ToUpper(r)) + w[size:]
+			}
+		case SnakeCase:
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+		case KebabCase:
+			if i > 0 {
+				sb.WriteByte('-')
+			}
+		}
+		sb.WriteString(w)
+	}
+	return sb.String()
+}
+
+// keyWords splits a key into words as described by ConvertKey.
+func keyWords(key string) []string {
+	var (
+		words []string
+		start = -1
+		prev  rune
+	)
+	for i, r := range key {
+		if r == '_' || r == '-' || r == ' ' || r == '.' {
+			if start >= 0 {
+				words = append(words, key[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			_, size := utf8.DecodeRuneInString(key[i:])
+			next, _ := utf8.DecodeRuneInString(key[i+size:])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && unicode.IsLower(next) {
+				words = append(words, key[start:i])
+				start = -1
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+		prev = r
+	}
+	if start >= 0 {
+		words = append(words, key[start:])
+	}
+	return words
+}
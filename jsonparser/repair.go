@@ -0,0 +1,438 @@
+package jsonparser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fix describes a change Repair made to its input.
+type Fix struct {
+	// Pos is the position in the original input the fix applies to.
+	Pos Position
+	Msg string
+}
+
+func (f Fix) String() string {
+	return fmt.Sprintf("line %d, column %d: %s", f.Pos.Line, f.Pos.Col, f.Msg)
+}
+
+// Repair makes a best-effort attempt to turn broken input, such as
+// truncated logs or hand-edited documents, into valid JSON before parsing it.
+// It closes unterminated strings and brackets, converts single-quoted strings,
+// quotes unquoted keys, removes trailing commas and comments, and inserts
+// missing commas, colons and values.
+//
+// Repair returns the parsed document along with the fixes applied.
+// Spans of the returned elements refer to the repaired text,
+// which can be reproduced with Minify.
+func Repair(b []byte, opts ...Option) (*Element, []Fix, error) {
+	rp := &repairer{parser: *newParser(b)}
+	rp.repair()
+
+	el, err := Parse(rp.out, opts...)
+	if err != nil {
+		return nil, rp.fixes, err
+	}
+	return el, rp.fixes, nil
+}
+
+type repairState uint8
+
+const (
+	// repairValue expects a value, which in an object follows the colon.
+	repairValue repairState = iota
+	// repairKey expects a member key or the end of the object.
+	repairKey
+	// repairColon expects the colon after a member key.
+	repairColon
+	// repairNext expects a comma or the end of the container.
+	repairNext
+)
+
+type repairFrame struct {
+	closer byte
+	state  repairState
+	// comma is the index in the output of the last comma
+	// written in the container, or -1.
+	comma int
+}
+
+type repairer struct {
+	parser
+	out   []byte
+	fixes []Fix
+	stack []repairFrame
+	// done is set once the root value is complete.
+	done bool
+}
+
+func (rp *repairer) fix(pos Position, format string, args ...any) {
+	rp.fixes = append(rp.fixes, Fix{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (rp *repairer) repair() {
+	for {
+		rp.skip()
+		if rp.r.isEOF() {
+			break
+		}
+		if rp.done {
+			rp.fix(rp.r.pos(), "removed trailing content")
+			break
+		}
+
+		switch r, _ := rp.r.peek(); r {
+		case '}', ']':
+			rp.close(byte(r))
+		case ',':
+			rp.comma()
+		case ':':
+			rp.colon()
+		default:
+			rp.token()
+		}
+	}
+
+	for len(rp.stack) != 0 {
+		f := rp.stack[len(rp.stack)-1]
+		rp.fix(rp.r.pos(), "inserted missing %q", f.closer)
+		rp.closeTop()
+	}
+}
+
+// skip copies whitespace to the output and drops comments.
+func (rp *repairer) skip() {
+	for !rp.r.isEOF() {
+		r, _ := rp.r.peek()
+		if r == '/' {
+			pos := rp.r.pos()
+			if rp.skipComment() {
+				rp.fix(pos, "removed comment")
+				continue
+			}
+		}
+		if !isWhitespace(r) {
+			return
+		}
+		rp.out = utf8.AppendRune(rp.out, rp.r.read())
+	}
+}
+
+func (rp *repairer) top() *repairFrame {
+	if len(rp.stack) == 0 {
+		return nil
+	}
+	return &rp.stack[len(rp.stack)-1]
+}
+
+func (rp *repairer) close(closer byte) {
+	pos := rp.r.pos()
+	i := len(rp.stack) - 1
+	for i >= 0 && rp.stack[i].closer != closer {
+		i--
+	}
+	if i < 0 {
+		rp.r.read()
+		rp.fix(pos, "removed unexpected %q", closer)
+		return
+	}
+
+	for len(rp.stack)-1 > i {
+		rp.fix(pos, "inserted missing %q", rp.top().closer)
+		rp.closeTop()
+	}
+	// fixes completing the container refer to its closing bracket
+	rp.closeTop()
+	rp.r.read()
+}
+
+// closeTop completes the innermost container and writes its closing bracket.
+func (rp *repairer) closeTop() {
+	pos := rp.r.pos()
+	f := rp.top()
+	switch {
+	case f.state == repairColon:
+		rp.fix(pos, "inserted missing value")
+		rp.out = append(rp.out, ":null"...)
+	case f.state == repairValue && f.closer == '}':
+		rp.fix(pos, "inserted missing value")
+		rp.out = append(rp.out, "null"...)
+	case f.state != repairNext && f.comma >= 0:
+		rp.fix(pos, "removed trailing comma")
+		rp.out = slices.Delete(rp.out, f.comma, f.comma+1)
+	}
+
+	rp.out = append(rp.out, f.closer)
+	rp.stack = rp.stack[:len(rp.stack)-1]
+	rp.valueDone()
+}
+
+func (rp *repairer) valueDone() {
+	if f := rp.top(); f != nil {
+		f.state = repairNext
+	} else {
+		rp.done = true
+	}
+}
+
+func (rp *repairer) comma() {
+	pos := rp.r.pos()
+	f := rp.top()
+	switch {
+	case f != nil && f.state == repairNext:
+		rp.r.read()
+		f.comma = len(rp.out)
+		rp.out = append(rp.out, ',')
+		if f.closer == '}' {
+			f.state = repairKey
+		} else {
+			f.state = repairValue
+		}
+	case f != nil && f.closer == '}' && f.state != repairKey:
+		// the member has no value, complete it before the comma
+		if f.state == repairColon {
+			rp.out = append(rp.out, ':')
+		}
+		rp.out = append(rp.out, "null"...)
+		rp.fix(pos, "inserted missing value")
+		f.state = repairNext
+	default:
+		rp.r.read()
+		rp.fix(pos, "removed unexpected ','")
+	}
+}
+
+func (rp *repairer) colon() {
+	pos := rp.r.pos()
+	rp.r.read()
+	if f := rp.top(); f != nil && f.state == repairColon {
+		rp.out = append(rp.out, ':')
+		f.state = repairValue
+		return
+	}
+	rp.fix(pos, "removed unexpected ':'")
+}
+
+func (rp *repairer) token() {
+	pos := rp.r.pos()
+	f := rp.top()
+	if f == nil {
+		rp.value()
+		return
+	}
+
+	switch f.state {
+	case repairNext:
+		rp.fix(pos, "inserted missing comma")
+		f.comma = len(rp.out)
+		rp.out = append(rp.out, ',')
+		if f.closer == '}' {
+			f.state = repairKey
+		} else {
+			f.state = repairValue
+		}
+	case repairColon:
+		rp.fix(pos, "inserted missing colon")
+		rp.out = append(rp.out, ':')
+		f.state = repairValue
+	case repairKey:
+		rp.key()
+	case repairValue:
+		rp.value()
+	}
+}
+
+func (rp *repairer) key() {
+	pos := rp.r.pos()
+	switch r, _ := rp.r.peek(); r {
+	case '"':
+		rp.r.read()
+		rp.string('"')
+	case '\'':
+		rp.r.read()
+		rp.fix(pos, "replaced single quotes")
+		rp.string('\'')
+	default:
+		word := rp.word(func(r rune) bool {
+			return !isWhitespace(r) && !strings.ContainsRune(`:,{}[]"'/`, r)
+		})
+		if word == "" {
+			rp.fix(pos, "removed unexpected %q", rp.r.read())
+			return
+		}
+		rp.fix(pos, "quoted key %q", word)
+		rp.out = append(rp.out, '"')
+		rp.out = append(rp.out, escapeString(word)...)
+		rp.out = append(rp.out, '"')
+	}
+	rp.top().state = repairColon
+}
+
+func (rp *repairer) value() {
+	pos := rp.r.pos()
+	r, _ := rp.r.peek()
+	switch {
+	case r == '{':
+		rp.r.read()
+		rp.out = append(rp.out, '{')
+		rp.stack = append(rp.stack, repairFrame{closer: '}', state: repairKey, comma: -1})
+		return
+	case r == '[':
+		rp.r.read()
+		rp.out = append(rp.out, '[')
+		rp.stack = append(rp.stack, repairFrame{closer: ']', state: repairValue, comma: -1})
+		return
+	case r == '"':
+		rp.r.read()
+		rp.string('"')
+	case r == '\'':
+		rp.r.read()
+		rp.fix(pos, "replaced single quotes")
+		rp.string('\'')
+	case r == '-' || r == '+' || r == '.' || isDigit(r):
+		rp.number()
+	case isIdentifierStart(r):
+		rp.literal()
+	default:
+		rp.fix(pos, "removed unexpected %q", rp.r.read())
+		return
+	}
+	rp.valueDone()
+}
+
+// string copies the rest of a string opened with quote to the output
+// as a double-quoted string.
+func (rp *repairer) string(quote rune) {
+	rp.out = append(rp.out, '"')
+	for {
+		if rp.r.isEOF() {
+			rp.fix(rp.r.pos(), "closed unterminated string")
+			break
+		}
+
+		pos := rp.r.pos()
+		r := rp.r.read()
+		switch {
+		case r == quote:
+			rp.out = append(rp.out, '"')
+			return
+		case r == '"':
+			rp.out = append(rp.out, `\"`...)
+		case r == '\\':
+			switch e, _ := rp.r.peek(); {
+			case e == '\'' && quote == '\'':
+				rp.r.read()
+				rp.out = append(rp.out, '\'')
+			case strings.ContainsRune(`"\/bfnrtu`, e) && !rp.r.isEOF():
+				rp.r.read()
+				rp.out = append(rp.out, '\\')
+				rp.out = utf8.AppendRune(rp.out, e)
+			default:
+				rp.fix(pos, "escaped backslash")
+				rp.out = append(rp.out, `\\`...)
+			}
+		case isSpecialCharacter(r):
+			rp.fix(pos, "escaped control character %q", r)
+			rp.out = append(rp.out, escapeString(string(r))...)
+		default:
+			rp.out = utf8.AppendRune(rp.out, r)
+		}
+	}
+	rp.out = append(rp.out, '"')
+}
+
+// jsonNumber matches the number grammar of RFC 8259.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func (rp *repairer) number() {
+	pos := rp.r.pos()
+	// letters are part of the word so that -Infinity and 1abc
+	// are not split into several values
+	num := rp.word(func(r rune) bool {
+		return isIdentifierPart(r) || strings.ContainsRune("+-.", r)
+	})
+
+	if rest := num[1:]; (num[0] == '-' || num[0] == '+') && (rest == "Infinity" || rest == "NaN") {
+		rp.fix(pos, "replaced %q with null", num)
+		rp.out = append(rp.out, "null"...)
+		return
+	}
+
+	fixed := strings.TrimPrefix(num, "+")
+	if strings.HasPrefix(fixed, ".") || strings.HasPrefix(fixed, "-.") {
+		fixed = strings.Replace(fixed, ".", "0.", 1)
+	}
+	fixed = strings.TrimRight(fixed, ".eE+-")
+	if fixed == "" || fixed == "-" {
+		fixed = "0"
+	}
+	// leading zeros, keeping the zero of 0 and 0.5
+	sign, digits := "", fixed
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if trimmed := strings.TrimLeft(digits, "0"); trimmed == "" || !isDigit(rune(trimmed[0])) {
+		digits = "0" + trimmed
+	} else {
+		digits = trimmed
+	}
+	fixed = sign + digits
+
+	switch {
+	case !jsonNumber.MatchString(fixed):
+		rp.fix(pos, "quoted %q", num)
+		rp.out = append(rp.out, '"')
+		rp.out = append(rp.out, escapeString(num)...)
+		rp.out = append(rp.out, '"')
+		return
+	case fixed != num:
+		rp.fix(pos, "replaced number %q with %s", num, fixed)
+	}
+	rp.out = append(rp.out, fixed...)
+}
+
+func (rp *repairer) literal() {
+	pos := rp.r.pos()
+	word := rp.word(isIdentifierPart)
+
+	var fixed string
+	switch word {
+	case "true", "false", "null":
+		rp.out = append(rp.out, word...)
+		return
+	case "True", "False", "None":
+		fixed = map[string]string{"True": "true", "False": "false", "None": "null"}[word]
+	case "NaN", "Infinity", "undefined":
+		fixed = "null"
+	default:
+		for _, lit := range []string{"true", "false", "null"} {
+			if rp.r.isEOF() && strings.HasPrefix(lit, word) {
+				fixed = lit
+			}
+		}
+	}
+
+	if fixed == "" {
+		rp.fix(pos, "quoted %q", word)
+		fixed = `"` + string(escapeString(word)) + `"`
+	} else {
+		rp.fix(pos, "replaced %q with %s", word, fixed)
+	}
+	rp.out = append(rp.out, fixed...)
+}
+
+// word reads the longest run of runes satisfying f.
+func (rp *repairer) word(f func(rune) bool) string {
+	var sb strings.Builder
+	for !rp.r.isEOF() {
+		r, _ := rp.r.peek()
+		if !f(r) {
+			break
+		}
+		sb.WriteRune(rp.r.read())
+	}
+	return sb.String()
+}
@@ -0,0 +1,216 @@
+package jsonparser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		fixes []string
+	}{
+		{
+			name:  "valid input",
+			input: `{"a": [1, "x", null]}`,
+			want:  `{"a":[1,"x",null]}`,
+		},
+		{
+			name:  "missing comma between members",
+			input: `{"a": 1 "b": 2}`,
+			want:  `{"a":1,"b":2}`,
+			fixes: []string{"line 1, column 9: inserted missing comma"},
+		},
+		{
+			name:  "missing commas between elements",
+			input: `[true false null]`,
+			want:  `[true,false,null]`,
+			fixes: []string{"line 1, column 7: inserted missing comma", "line 1, column 13: inserted missing comma"},
+		},
+		{
+			name:  "missing brackets",
+			input: `{"a": {"b": [1, {"c": 2`,
+			want:  `{"a":{"b":[1,{"c":2}]}}`,
+			fixes: []string{
+				"line 1, column 24: inserted missing '}'",
+				"line 1, column 24: inserted missing ']'",
+				"line 1, column 24: inserted missing '}'",
+				"line 1, column 24: inserted missing '}'",
+			},
+		},
+		{
+			name:  "mismatched bracket",
+			input: `{"a": [1, 2}`,
+			want:  `{"a":[1,2]}`,
+			fixes: []string{"line 1, column 12: inserted missing ']'"},
+		},
+		{
+			name:  "unexpected bracket",
+			input: `[1}`,
+			want:  `[1]`,
+			fixes: []string{"line 1, column 3: removed unexpected '}'", "line 1, column 4: inserted missing ']'"},
+		},
+		{
+			name:  "unterminated string",
+			input: `{"a": "abc`,
+			want:  `{"a":"abc"}`,
+			fixes: []string{"line 1, column 11: closed unterminated string", "line 1, column 11: inserted missing '}'"},
+		},
+		{
+			name:  "unterminated string ending in a backslash",
+			input: `["x\`,
+			want:  `["x\\"]`,
+			fixes: []string{"line 1, column 4: escaped backslash", "line 1, column 5: closed unterminated string", "line 1, column 5: inserted missing ']'"},
+		},
+		{
+			name:  "raw line break in string",
+			input: "[\"line\nbreak\"]",
+			want:  `["line\nbreak"]`,
+			fixes: []string{`line 1, column 7: escaped control character '\n'`},
+		},
+		{
+			name:  "single quotes",
+			input: `{'a': 'b'}`,
+			want:  `{"a":"b"}`,
+			fixes: []string{"line 1, column 2: replaced single quotes", "line 1, column 7: replaced single quotes"},
+		},
+		{
+			name:  "unquoted key",
+			input: `{a: 1}`,
+			want:  `{"a":1}`,
+			fixes: []string{`line 1, column 2: quoted key "a"`},
+		},
+		{
+			name:  "trailing commas",
+			input: `{"a": [1, 2,],}`,
+			want:  `{"a":[1,2]}`,
+			fixes: []string{"line 1, column 13: removed trailing comma", "line 1, column 15: removed trailing comma"},
+		},
+		{
+			name:  "repeated comma",
+			input: `[1,,2]`,
+			want:  `[1,2]`,
+			fixes: []string{"line 1, column 4: removed unexpected ','"},
+		},
+		{
+			name:  "comments",
+			input: "[1, // one\n2 /* two */]",
+			want:  `[1,2]`,
+			fixes: []string{"line 1, column 5: removed comment", "line 2, column 3: removed comment"},
+		},
+		{
+			name:  "missing colon",
+			input: `{"a" 1}`,
+			want:  `{"a":1}`,
+			fixes: []string{"line 1, column 6: inserted missing colon"},
+		},
+		{
+			name:  "missing values",
+			input: `{"a":, "b"}`,
+			want:  `{"a":null,"b":null}`,
+			fixes: []string{"line 1, column 6: inserted missing value", "line 1, column 11: inserted missing value"},
+		},
+		{
+			name:  "truncated literal",
+			input: `{"a": tru`,
+			want:  `{"a":true}`,
+			fixes: []string{`line 1, column 7: replaced "tru" with true`, "line 1, column 10: inserted missing '}'"},
+		},
+		{
+			name:  "foreign literals",
+			input: `[None, True, NaN, undefined]`,
+			want:  `[null,true,null,null]`,
+			fixes: []string{
+				`line 1, column 2: replaced "None" with null`,
+				`line 1, column 8: replaced "True" with true`,
+				`line 1, column 14: replaced "NaN" with null`,
+				`line 1, column 19: replaced "undefined" with null`,
+			},
+		},
+		{
+			name:  "bare word",
+			input: `[abc]`,
+			want:  `["abc"]`,
+			fixes: []string{`line 1, column 2: quoted "abc"`},
+		},
+		{
+			name:  "non-ASCII bare word",
+			input: `{"a": héllo}`,
+			want:  `{"a":"héllo"}`,
+			fixes: []string{`line 1, column 7: quoted "héllo"`},
+		},
+		{
+			name:  "signed non-finite numbers",
+			input: `[-Infinity, +Infinity, -NaN]`,
+			want:  `[null,null,null]`,
+			fixes: []string{
+				`line 1, column 2: replaced "-Infinity" with null`,
+				`line 1, column 13: replaced "+Infinity" with null`,
+				`line 1, column 24: replaced "-NaN" with null`,
+			},
+		},
+		{
+			name:  "invalid numbers",
+			input: `[1-2, 1e5e5, 0x1F, 12abc]`,
+			want:  `["1-2","1e5e5","0x1F","12abc"]`,
+			fixes: []string{
+				`line 1, column 2: quoted "1-2"`,
+				`line 1, column 7: quoted "1e5e5"`,
+				`line 1, column 14: quoted "0x1F"`,
+				`line 1, column 20: quoted "12abc"`,
+			},
+		},
+		{
+			name:  "relaxed numbers",
+			input: `[.5, 007, +1, 00.5, 1.]`,
+			want:  `[0.5,7,1,0.5,1]`,
+			fixes: []string{
+				`line 1, column 2: replaced number ".5" with 0.5`,
+				`line 1, column 6: replaced number "007" with 7`,
+				`line 1, column 11: replaced number "+1" with 1`,
+				`line 1, column 15: replaced number "00.5" with 0.5`,
+				`line 1, column 21: replaced number "1." with 1`,
+			},
+		},
+		{
+			name:  "numbers kept",
+			input: `[0, -0, 0.5, -10e-3]`,
+			want:  `[0,-0,0.5,-10e-3]`,
+		},
+		{
+			name:  "trailing content",
+			input: `{"a": 1}}`,
+			want:  `{"a":1}`,
+			fixes: []string{"line 1, column 9: removed trailing content"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixes, err := Repair([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := Minify(got); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+			var msgs []string
+			for _, fix := range fixes {
+				msgs = append(msgs, fix.String())
+			}
+			if !slices.Equal(msgs, tt.fixes) {
+				t.Errorf("got fixes %q, want %q", msgs, tt.fixes)
+			}
+		})
+	}
+}
+
+func TestRepairOptions(t *testing.T) {
+	// the options apply to parsing the repaired text
+	_, _, err := Repair([]byte(`{"a": 1, "a": 2`), WithDuplicateKeyPolicy(DuplicateKeysReject))
+	if err == nil {
+		t.Error("expected duplicate key error")
+	}
+}
@@ -0,0 +1,32 @@
+package jsonparser
+
+// Reviver transforms a value during parsing. It receives the JSON Pointer
+// of the value and returns the element to keep in its place,
+// or false to drop the value from its object or array.
+type Reviver func(ptr string, el *Element) (*Element, bool)
+
+// WithReviver calls fn for every value as soon as it is parsed, like the
+// reviver of JavaScript's JSON.parse. Children are passed before their
+// parent, which receives the transformed children.
+// Dropping the root makes parsing return a nil element.
+func WithReviver(fn Reviver) Option {
+	return func(c *config) {
+		c.reviver = fn
+	}
+}
+
+func (p *parser) tracksPath() bool {
+	return p.cfg.paths != nil || p.cfg.reviver != nil
+}
+
+// revive passes el to the reviver and returns its replacement,
+// or nil if it was dropped.
+func (p *parser) revive(el *Element) *Element {
+	if p.cfg.reviver == nil || el == nil {
+		return el
+	}
+	if el, keep := p.cfg.reviver(joinPointer(p.path), el); keep {
+		return el
+	}
+	return nil
+}
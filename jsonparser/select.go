@@ -0,0 +1,80 @@
+package jsonparser
+
+// WithPaths makes the parser decode only the values at the given
+// JSON Pointers, together with their ancestors, and skip everything else
+// without decoding it. A "*" reference token matches any member or
+// array element. Arrays keep only their selected elements, so indices
+// in the result may differ from the input. Skipped values are not validated.
+//
+// Parsing fails if a pointer is malformed.
+func WithPaths(pointers ...string) Option {
+	return func(c *config) {
+		c.paths = [][]string{}
+		for _, ptr := range pointers {
+			tokens, err := splitPointer(ptr)
+			if err != nil {
+				c.err = err
+				return
+			}
+			c.paths = append(c.paths, tokens)
+		}
+	}
+}
+
+// selected reports whether the value at the current path
+// is on the way to, or inside of, a selected value.
+func (p *parser) selected() bool {
+	if p.cfg.paths == nil {
+		return true
+	}
+	for _, sel := range p.cfg.paths {
+		if pathsOverlap(p.path, sel) {
+			return true
+		}
+	}
+	return false
+}
+
+func pathsOverlap(path, sel []string) bool {
+	for i := 0; i < len(path) && i < len(sel); i++ {
+		if sel[i] != "*" && sel[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) popPath() {
+	p.path = p.path[:len(p.path)-1]
+}
+
+// skipValue skips a value without decoding or validating it.
+func (p *parser) skipValue() {
+	var depth int
+	for !p.r.isEOF() {
+		r, _ := p.r.peek()
+		switch {
+		case r == '"':
+			p.r.read()
+			p.skipString()
+			if depth == 0 {
+				return
+			}
+			continue
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			if depth == 0 {
+				return
+			}
+			depth--
+			if depth == 0 {
+				p.r.read()
+				return
+			}
+		case depth == 0 && (r == ',' || isWhitespace(r)):
+			return
+		}
+		p.r.read()
+	}
+}
@@ -0,0 +1,118 @@
+package jsonparser
+
+import "fmt"
+
+// PointerOption configures SetAtPointer.
+type PointerOption func(*pointerConfig)
+
+type pointerConfig struct {
+	createParents bool
+	arrayParents  bool
+}
+
+// WithCreateParents creates the containers missing on the way to the
+// target, and replaces null ones, as objects.
+func WithCreateParents() PointerOption {
+	return func(c *pointerConfig) {
+		c.createParents = true
+	}
+}
+
+// WithArrayParents makes WithCreateParents create arrays instead of
+// objects for the containers followed by an array index or "-".
+func WithArrayParents() PointerOption {
+	return func(c *pointerConfig) {
+		c.arrayParents = true
+	}
+}
+
+// SetAtPointer sets the value referenced by the JSON Pointer ptr to val.
+// Object members are replaced or added. Array elements are replaced,
+// and the index past the last element or "-" appends to the array.
+// An empty pointer replaces the document in place. The containers on
+// the way to the target must exist unless WithCreateParents is given.
+// val is stored without being copied.
+func SetAtPointer(el *Element, ptr string, val *Element, opts ...PointerOption) error {
+	var cfg pointerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		el.ReplaceValue(val)
+		return nil
+	}
+
+	parent := el
+	for i, t := range tokens[:len(tokens)-1] {
+		next, err := child(parent, t)
+		if err == nil && !(cfg.createParents && next.kind == NullKind) {
+			parent = next
+			continue
+		}
+		if !cfg.createParents || parent.kind != ObjectKind && parent.kind != ArrayKind {
+			return fmt.Errorf("cannot resolve %q: %w", joinPointer(tokens[:i+1]), err)
+		}
+		next = &Element{kind: ObjectKind, value: []Member{}}
+		if cfg.arrayParents && isArrayToken(tokens[i+1]) {
+			next = &Element{kind: ArrayKind, value: []*Element{}}
+		}
+		if err := setChild(parent, t, next); err != nil {
+			return fmt.Errorf("cannot set %q: %w", joinPointer(tokens[:i+1]), err)
+		}
+		parent = next
+	}
+	if err := setChild(parent, tokens[len(tokens)-1], val); err != nil {
+		return fmt.Errorf("cannot set %q: %w", ptr, err)
+	}
+	return nil
+}
+
+// DeleteAtPointer removes the object member or array element referenced
+// by the JSON Pointer ptr, which must exist. Later array elements shift.
+func DeleteAtPointer(el *Element, ptr string) error {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return err
+	}
+	_, err = patchRemove(el, tokens)
+	return err
+}
+
+// setChild sets the member or element of parent referenced by token.
+func setChild(parent *Element, token string, val *Element) error {
+	parent.load()
+	switch parent.kind {
+	case ObjectKind:
+		return parent.SetMember(token, val)
+	case ArrayKind:
+		elements := parent.value.([]*Element)
+		if token == "-" {
+			return parent.Append(val)
+		}
+		idx, err := arrayIndex(token)
+		if err != nil {
+			return err
+		}
+		switch {
+		case idx < len(elements):
+			elements[idx] = val
+			return nil
+		case idx == len(elements):
+			return parent.Append(val)
+		default:
+			return fmt.Errorf("index %d out of range [0:%d]", idx, len(elements))
+		}
+	default:
+		return fmt.Errorf("%s has no children", parent.kind)
+	}
+}
+
+// isArrayToken reports whether a reference token is an array index or "-".
+func isArrayToken(token string) bool {
+	_, err := arrayIndex(token)
+	return token == "-" || err == nil
+}
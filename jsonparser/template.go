@@ -0,0 +1,65 @@
+package jsonparser
+
+import (
+	"slices"
+	"text/template"
+)
+
+// TemplateMember is an object member as returned by the members
+// template function.
+type TemplateMember struct {
+	Key   string
+	Value any
+}
+
+// TemplateFuncs returns functions for text/template and html/template
+// templates executed with ToValue(root) as data:
+//
+//   - pointer ptr: the value referenced by the JSON Pointer ptr, converted by ToValue.
+//   - members ptr: the members of the object referenced by ptr in document order,
+//     as TemplateMember values.
+//   - keys value: the keys of an object value in sorted order.
+//   - json value: value encoded as minified JSON.
+//   - pretty value: value encoded as JSON indented with two spaces.
+func TemplateFuncs(root *Element) template.FuncMap {
+	return template.FuncMap{
+		"pointer": func(ptr string) (any, error) {
+			el, err := ResolvePointer(root, ptr)
+			if err != nil {
+				return nil, err
+			}
+			return ToValue(el), nil
+		},
+		"members": func(ptr string) ([]TemplateMember, error) {
+			el, err := ResolvePointer(root, ptr)
+			if err != nil {
+				return nil, err
+			}
+			members, err := el.members("list members")
+			if err != nil {
+				return nil, err
+			}
+			result := make([]TemplateMember, len(members))
+			for i, m := range members {
+				result[i] = TemplateMember{Key: m.Key(), Value: ToValue(m.value)}
+			}
+			return result, nil
+		},
+		"keys": func(m map[string]any) []string {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			return keys
+		},
+		"json": func(v any) (string, error) {
+			b, err := Marshal(v)
+			return string(b), err
+		},
+		"pretty": func(v any) (string, error) {
+			b, err := MarshalIndent(v, 2)
+			return string(b), err
+		},
+	}
+}
@@ -0,0 +1,55 @@
+package jsonparser
+
+// Visitor is called by Walk for every node of an element tree.
+// Returning false from an Enter method skips the children of the node,
+// the matching Leave method is still called.
+type Visitor interface {
+	EnterObject(el *Element) bool
+	LeaveObject(el *Element)
+	EnterMember(m Member) bool
+	LeaveMember(m Member)
+	EnterArray(el *Element) bool
+	LeaveArray(el *Element)
+	EnterScalar(el *Element)
+	LeaveScalar(el *Element)
+}
+
+// BaseVisitor implements Visitor with no-op methods and is meant to be
+// embedded into visitors that only care about some of the nodes.
+type BaseVisitor struct{}
+
+func (BaseVisitor) EnterObject(*Element) bool { return true }
+func (BaseVisitor) LeaveObject(*Element)      {}
+func (BaseVisitor) EnterMember(Member) bool   { return true }
+func (BaseVisitor) LeaveMember(Member)        {}
+func (BaseVisitor) EnterArray(*Element) bool  { return true }
+func (BaseVisitor) LeaveArray(*Element)       {}
+func (BaseVisitor) EnterScalar(*Element)      {}
+func (BaseVisitor) LeaveScalar(*Element)      {}
+
+// Walk traverses the element tree in depth-first order calling v for every node.
+func Walk(el *Element, v Visitor) {
+	el.load()
+	switch el.kind {
+	case ObjectKind:
+		if v.EnterObject(el) {
+			for _, m := range el.value.([]Member) {
+				if v.EnterMember(m) {
+					Walk(m.value, v)
+				}
+				v.LeaveMember(m)
+			}
+		}
+		v.LeaveObject(el)
+	case ArrayKind:
+		if v.EnterArray(el) {
+			for _, e := range el.value.([]*Element) {
+				Walk(e, v)
+			}
+		}
+		v.LeaveArray(el)
+	default:
+		v.EnterScalar(el)
+		v.LeaveScalar(el)
+	}
+}
@@ -0,0 +1,242 @@
+package jsonparser
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// XMLOption configures ToXML and WriteXML.
+type XMLOption func(*xmlConfig)
+
+type xmlConfig struct {
+	root       string
+	item       string
+	attrPrefix string
+	textKey    string
+	indent     string
+}
+
+// WithXMLRoot sets the name of the document element, "root" by default.
+func WithXMLRoot(name string) XMLOption {
+	return func(c *xmlConfig) {
+		c.root = name
+	}
+}
+
+// WithXMLItem sets the name of the elements holding the elements of arrays
+// that are not the value of an object member, "item" by default.
+func WithXMLItem(name string) XMLOption {
+	return func(c *xmlConfig) {
+		c.item = name
+	}
+}
+
+// WithXMLAttributePrefix sets the prefix of the keys of object members
+// written as attributes, "@" by default. An empty prefix writes every
+// member as an element.
+func WithXMLAttributePrefix(prefix string) XMLOption {
+	return func(c *xmlConfig) {
+		c.attrPrefix = prefix
+	}
+}
+
+// WithXMLTextKey sets the key of the object member written as
+// the text content of the element, "#text" by default.
+func WithXMLTextKey(key string) XMLOption {
+	return func(c *xmlConfig) {
+		c.textKey = key
+	}
+}
+
+// WithXMLIndent writes every element on its own line,
+// indented by indent per nesting level.
+func WithXMLIndent(indent string) XMLOption {
+	return func(c *xmlConfig) {
+		c.indent = indent
+	}
+}
+
+// ToXML converts the element to an XML document.
+//
+// Object members become child elements named after their keys,
+// except for those mapped to attributes and text content.
+// Array values of members repeat the element of the member once per
+// array element, and other arrays hold their elements in item elements.
+// Nulls become empty elements. Characters not allowed in XML names,
+// including colons, are replaced with underscores. Keys mapping to the
+// same attribute name of an element are an error.
+func ToXML(e *Element, opts ...XMLOption) (string, error) {
+	var sb strings.Builder
+	if err := WriteXML(&sb, e, opts...); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// WriteXML writes the element to w as an XML document, like ToXML.
+func WriteXML(w io.Writer, e *Element, opts ...XMLOption) error {
+	cfg := xmlConfig{root: "root", item: "item", attrPrefix: "@", textKey: "#text"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	x := xmlWriter{w: bufio.NewWriter(w), cfg: cfg}
+	x.w.WriteString(xml.Header[:len(xml.Header)-1])
+	if err := x.element(cfg.root, e, 0); err != nil {
+		return err
+	}
+	if cfg.indent != "" {
+		x.w.WriteRune('\n')
+	}
+	return x.w.Flush()
+}
+
+type xmlWriter struct {
+	w   *bufio.Writer
+	cfg xmlConfig
+}
+
+func (x *xmlWriter) newline(lvl int) {
+	if x.cfg.indent != "" {
+		x.w.WriteRune('\n')
+		x.w.WriteString(strings.Repeat(x.cfg.indent, lvl))
+	}
+}
+
+// element writes e as the element name at nesting level lvl.
+func (x *xmlWriter) element(name string, e *Element, lvl int) error {
+	e.load()
+	name = xmlName(name)
+	x.newline(lvl)
+	x.w.WriteString("<" + name)
+
+	switch e.kind {
+	case ObjectKind:
+		var (
+			text     *Element
+			children []Member
+			attrs    = make(map[string]bool)
+		)
+		for _, m := range e.value.([]Member) {
+			key := m.Key()
+			switch {
+			case key == x.cfg.textKey:
+				if !isScalar(m.value) {
+					return fmt.Errorf("text content %q of <%s> must be a scalar", key, name)
+				}
+				text = m.value
+			case x.cfg.attrPrefix != "" && strings.HasPrefix(key, x.cfg.attrPrefix):
+				if !isScalar(m.value) {
+					return fmt.Errorf("attribute %q of <%s> must be a scalar", key, name)
+				}
+				attr := xmlName(strings.TrimPrefix(key, x.cfg.attrPrefix))
+				if attrs[attr] {
+					return fmt.Errorf("duplicate attribute %q of <%s>", attr, name)
+				}
+				attrs[attr] = true
+				x.w.WriteString(" " + attr + `="`)
+				xml.EscapeText(x.w, []byte(xmlText(m.value)))
+				x.w.WriteRune('"')
+			default:
+				children = append(children, m)
+			}
+		}
+
+		if text == nil && len(children) == 0 {
+			x.w.WriteString("/>")
+			return nil
+		}
+		x.w.WriteRune('>')
+		if text != nil {
+			if len(children) != 0 {
+				x.newline(lvl + 1)
+			}
+			xml.EscapeText(x.w, []byte(xmlText(text)))
+		}
+		for _, m := range children {
+			if err := x.member(m, lvl+1); err != nil {
+				return err
+			}
+		}
+		if len(children) != 0 {
+			x.newline(lvl)
+		}
+	case ArrayKind:
+		elements := e.value.([]*Element)
+		if len(elements) == 0 {
+			x.w.WriteString("/>")
+			return nil
+		}
+		x.w.WriteRune('>')
+		for _, el := range elements {
+			if err := x.element(x.cfg.item, el, lvl+1); err != nil {
+				return err
+			}
+		}
+		x.newline(lvl)
+	case NullKind:
+		x.w.WriteString("/>")
+		return nil
+	default:
+		x.w.WriteRune('>')
+		xml.EscapeText(x.w, []byte(xmlText(e)))
+	}
+
+	x.w.WriteString("</" + name + ">")
+	return nil
+}
+
+// member writes the elements of an object member.
+func (x *xmlWriter) member(m Member, lvl int) error {
+	m.value.load()
+	if m.value.kind != ArrayKind {
+		return x.element(m.Key(), m.value, lvl)
+	}
+	for _, el := range m.value.value.([]*Element) {
+		if err := x.element(m.Key(), el, lvl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isScalar(e *Element) bool {
+	return e.kind != ObjectKind && e.kind != ArrayKind
+}
+
+// xmlText returns the text of a scalar.
+func xmlText(e *Element) string {
+	switch e.kind {
+	case StringKind:
+		return unescape(e.value.([]byte))
+	case NumberKind:
+		return numberText(e.value)
+	case BooleanKind:
+		return fmt.Sprintf("%v", e.value)
+	case NullKind:
+		return ""
+	}
+	panic("unreachable")
+}
+
+// xmlName replaces the characters of s that are not allowed in XML names.
+func xmlName(s string) string {
+	if s == "" {
+		return "_"
+	}
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i != 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
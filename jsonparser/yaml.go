@@ -0,0 +1,242 @@
+package jsonparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ToYAML converts the element to a YAML document in block style.
+// Multi-line strings are written as literal block scalars, and strings
+// that YAML would read as another type are quoted. Comments are dropped.
+func ToYAML(e *Element) string {
+	var sb strings.Builder
+	_ = WriteYAML(&sb, e)
+	return sb.String()
+}
+
+// WriteYAML writes the element to w as a YAML document, like ToYAML.
+func WriteYAML(w io.Writer, e *Element) error {
+	y := yamlWriter{w: bufio.NewWriter(w)}
+	y.node(e, 0, yamlRoot)
+	return y.w.Flush()
+}
+
+// yamlContext is what precedes a node on its first line.
+type yamlContext uint8
+
+const (
+	yamlRoot yamlContext = iota
+	yamlKey
+	yamlItem
+)
+
+type yamlWriter struct {
+	w *bufio.Writer
+}
+
+// node writes e with its nested lines indented by col spaces.
+func (y *yamlWriter) node(e *Element, col int, ctx yamlContext) {
+	e.load()
+	switch e.kind {
+	case ObjectKind:
+		if members := e.value.([]Member); len(members) != 0 {
+			y.open(ctx)
+			for i, m := range members {
+				if i != 0 || ctx != yamlItem {
+					y.indent(col)
+				}
+				y.w.WriteString(yamlKeyText(m.Key()))
+				y.w.WriteRune(':')
+				y.node(m.value, col+2, yamlKey)
+			}
+			return
+		}
+	case ArrayKind:
+		if elements := e.value.([]*Element); len(elements) != 0 {
+			y.open(ctx)
+			for i, el := range elements {
+				if i != 0 || ctx != yamlItem {
+					y.indent(col)
+				}
+				y.w.WriteRune('-')
+				y.node(el, col+2, yamlItem)
+			}
+			return
+		}
+	case StringKind:
+		if s := unescape(e.value.([]byte)); ctx != yamlRoot && isYAMLBlock(s) {
+			y.w.WriteRune(' ')
+			y.block(s, col)
+			return
+		}
+	}
+
+	if ctx != yamlRoot {
+		y.w.WriteRune(' ')
+	}
+	y.w.WriteString(yamlScalar(e))
+	y.w.WriteRune('\n')
+}
+
+// open starts the first line of a non-empty object or array.
+func (y *yamlWriter) open(ctx yamlContext) {
+	switch ctx {
+	case yamlKey:
+		y.w.WriteRune('\n')
+	case yamlItem:
+		y.w.WriteRune(' ')
+	}
+}
+
+func (y *yamlWriter) indent(col int) {
+	y.w.WriteString(strings.Repeat(" ", col))
+}
+
+// block writes s as a literal block scalar with its lines indented by col spaces.
+func (y *yamlWriter) block(s string, col int) {
+	body := strings.TrimRight(s, "\n")
+	newlines := len(s) - len(body)
+
+	y.w.WriteRune('|')
+	if body[0] == ' ' || body[0] == '\n' {
+		// the indentation cannot be detected from the first line
+		y.w.WriteRune('2')
+	}
+	switch newlines {
+	case 0:
+		y.w.WriteRune('-')
+	case 1:
+	default:
+		y.w.WriteRune('+')
+	}
+	y.w.WriteRune('\n')
+
+	for _, line := range strings.Split(body, "\n") {
+		if line != "" {
+			y.indent(col)
+			y.w.WriteString(line)
+		}
+		y.w.WriteRune('\n')
+	}
+	for i := 1; i < newlines; i++ {
+		y.w.WriteRune('\n')
+	}
+}
+
+// yamlScalar returns the text of a scalar, an empty object or an empty array.
+func yamlScalar(e *Element) string {
+	switch e.kind {
+	case ObjectKind:
+		return "{}"
+	case ArrayKind:
+		return "[]"
+	case StringKind:
+		s := unescape(e.value.([]byte))
+		if isYAMLPlain(s) {
+			return s
+		}
+		return yamlQuote(s)
+	case NumberKind:
+		switch text := numberText(e.value); text {
+		case "NaN":
+			return ".nan"
+		case "Infinity":
+			return ".inf"
+		case "-Infinity":
+			return "-.inf"
+		default:
+			return text
+		}
+	case BooleanKind:
+		return fmt.Sprintf("%v", e.value)
+	case NullKind:
+		return "null"
+	}
+	panic("unreachable")
+}
+
+func yamlKeyText(key string) string {
+	if isYAMLPlain(key) {
+		return key
+	}
+	return yamlQuote(key)
+}
+
+// yamlReserved holds the plain scalars that YAML 1.1 or 1.2
+// read as something other than a string, compared in lower case.
+var yamlReserved = map[string]bool{
+	"~": true, "null": true, "true": true, "false": true,
+	"yes": true, "no": true, "on": true, "off": true, "y": true, "n": true,
+	"<<": true, "=": true,
+}
+
+// isYAMLPlain reports whether s can be written as a plain scalar
+// that is read back as the same string.
+func isYAMLPlain(s string) bool {
+	if s == "" || yamlReserved[strings.ToLower(s)] {
+		return false
+	}
+	// indicators, and the start of numbers and special floats
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@` .+0123456789", rune(s[0])) {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.HasSuffix(s, ":") || strings.HasSuffix(s, " ") {
+		return false
+	}
+	for _, r := range s {
+		if !isYAMLPrintable(r) || r == '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// isYAMLBlock reports whether s is a multi-line string
+// that can be written as a literal block scalar.
+func isYAMLBlock(s string) bool {
+	if !strings.Contains(s, "\n") || strings.Trim(s, "\n") == "" {
+		return false
+	}
+	for _, r := range s {
+		if !isYAMLPrintable(r) && r != '\n' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+func isYAMLPrintable(r rune) bool {
+	return r != utf8.RuneError && r != '\ufeff' && unicode.IsPrint(r)
+}
+
+// yamlQuote returns s as a double-quoted scalar.
+func yamlQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteRune('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case isYAMLPrintable(r):
+			sb.WriteRune(r)
+		case r > 0xffff:
+			fmt.Fprintf(&sb, `\U%08x`, r)
+		default:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		}
+	}
+	sb.WriteRune('"')
+	return sb.String()
+}
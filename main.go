@@ -1,551 +1,473 @@
 package main
 
 import (
+	"bytes"
 	"errors"
-	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
-	"unicode/utf8"
-)
-
-func main() {
-	if err := run(); err != nil {
-		log.Fatal(err.Error())
-	}
-}
-
-func run() error {
-	mode := flag.String("mode", "ast", "one of ast|pretty|minify")
-	flag.Parse()
-
-	if len(flag.Args()) < 1 {
-		return errors.New("path to JSON is required")
-	}
-	b, err := os.ReadFile(flag.Args()[0])
-	if err != nil {
-		return err
-	}
-	p := newParser(b)
-	json, err := p.parse()
-	if err != nil {
-		return err
-	}
 
-	switch *mode {
-	case "ast":
-		fmt.Println(astToString(json))
-	case "pretty":
-		fmt.Println(pretty(json, 2))
-	case "minify":
-		fmt.Println(minify(json))
-	default:
-		panic(fmt.Sprintf("unsupported mode: %q", *mode))
-	}
-	return nil
-}
-
-type elementKind uint8
-
-func (k elementKind) String() string {
-	switch k {
-	case objectKind:
-		return "object"
-	case arrayKind:
-		return "array"
-	case stringKind:
-		return "string"
-	case numberKind:
-		return "number"
-	case booleanKind:
-		return "boolean"
-	case nullKind:
-		return "null"
-	}
-	panic("unreachable")
-}
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
 
+// Exit codes besides 0 for success, one per class of failures.
+// If several inputs fail, the highest code is used.
 const (
-	objectKind elementKind = iota + 1
-	arrayKind
-	stringKind
-	numberKind
-	booleanKind
-	nullKind
+	// exitInvalid reports malformed documents, differences found by diff
+	// and -check, and failures not covered by the other codes.
+	exitInvalid = 1
+	// exitUsage reports invalid flags and arguments.
+	exitUsage = 2
+	// exitIO reports inputs which cannot be read and outputs
+	// which cannot be written.
+	exitIO = 3
+	// exitStrict reports documents rejected because of -strict:
+	// duplicate keys, invalid UTF-8 and inexact numbers.
+	exitStrict = 4
 )
 
-type pair struct {
-	key   []byte
-	value *jsonElement
+// exitError makes the program exit with code, logging err unless it is nil.
+type exitError struct {
+	code int
+	err  error
 }
 
-type jsonElement struct {
-	kind  elementKind
-	value any
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
 }
 
-type reader struct {
-	s      []byte
-	line   int
-	col    int
-	offset int
-}
+func (e *exitError) Unwrap() error { return e.err }
 
-func (r *reader) isEOF() bool {
-	return r.offset >= len(r.s)
+// usageErrorf formats an error about invalid flags or arguments.
+func usageErrorf(format string, a ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, a...)}
 }
 
-func (r *reader) peek() (v rune, size int) {
-	if r.isEOF() {
-		return v, 0
-	}
-	v, size = rune(r.s[r.offset]), 1
-	// check if rune is base ASCII character
-	if v >= 128 {
-		v, size = utf8.DecodeRune(r.s[r.offset:])
-		if v == utf8.RuneError && size == 1 {
-			v = rune(r.s[r.offset]) // illegal encoding
+// exitCode returns the exit code for the class of err,
+// the highest one if it joins several errors.
+func exitCode(err error) int {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var code int
+		for _, err := range joined.Unwrap() {
+			code = max(code, exitCode(err))
 		}
+		return code
+	}
+
+	var exit *exitError
+	switch {
+	case errors.As(err, &exit):
+		return exit.code
+	case errors.As(err, new(*fs.PathError)), errors.As(err, new(*url.Error)):
+		return exitIO
+	case errors.Is(err, jsonparser.ErrDuplicateKey), errors.Is(err, jsonparser.ErrInvalidUTF8),
+		errors.Is(err, jsonparser.ErrInexactNumber):
+		return exitStrict
+	default:
+		return exitInvalid
 	}
-	return v, size
 }
 
-func (r *reader) read() (v rune) {
-	v, s := r.peek()
-	if r.isEOF() {
-		return v
-	}
-	if v == '\n' {
-		r.col = 0
-		r.line++
-	} else {
-		r.col++
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		var exit *exitError
+		if !errors.As(err, &exit) || exit.err != nil {
+			log.Print(err)
+		}
+		os.Exit(exitCode(err))
 	}
-	r.offset += s
-	return v
 }
 
-type parser struct {
-	r reader
-}
+// programName is the name of the program in usage texts.
+var programName = filepath.Base(os.Args[0])
 
-func newParser(s []byte) *parser {
-	return &parser{
-		r: reader{s: s, line: 1, col: 0},
+// run runs the command named by the first argument.
+func run(args []string) error {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return &exitError{code: exitUsage}
 	}
-}
-
-func (p *parser) parse() (*jsonElement, error) {
-	return p.parseRoot()
-}
 
-func (p *parser) parseRoot() (*jsonElement, error) {
-	p.eatWhitespace()
-	root, err := p.parseValue()
-	if err != nil {
-		return nil, err
-	}
-	p.eatWhitespace()
-	if !p.r.isEOF() {
-		return nil, p.expectedError("eof", p.r.read())
+	name, args := args[0], args[1:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 0 {
+			if c := findCommand(args[0]); c != nil {
+				fs, _ := c.flagSet()
+				fs.SetOutput(os.Stdout)
+				fs.Usage()
+				return nil
+			}
+		}
+		usage(os.Stdout)
+		return nil
 	}
 
-	return root, nil
-}
-
-func (p *parser) parseValue() (el *jsonElement, err error) {
-	r := p.r.read()
-	switch r {
-	case '{':
-		el, err = p.parseObject()
-	case '[':
-		el, err = p.parseArray()
-	case '"':
-		el, err = p.parseString()
-	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-		el, err = p.parseNumber(r)
-	case 't', 'f':
-		el, err = p.parseBool(r)
-	case 'n':
-		el, err = p.parseNull()
-	default:
-		return el, p.syntaxError(
-			fmt.Errorf("unexpected token: %q", r),
-		)
+	c := findCommand(name)
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		return &exitError{code: exitUsage}
 	}
-	return
+	return c.run(args)
 }
 
-func (p *parser) parseObject() (*jsonElement, error) {
-	p.eatWhitespace()
-
-	var members []*pair
-
-	for !p.r.isEOF() {
-		if r, _ := p.r.peek(); r == '}' {
-			break
-		}
-
-		if len(members) != 0 {
-			r := p.r.read()
-			if r != ',' {
-				return nil, p.expectedError(",", r)
-			}
-			p.eatWhitespace()
+// usage writes the list of commands to w.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: %s <command> [flags] [arguments]\n\ncommands:\n", programName)
+	for _, c := range commands() {
+		if c.hidden {
+			continue
 		}
-
-		member, err := p.parseMember()
-		if err != nil {
-			return nil, err
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.help)
+	}
+	fmt.Fprintf(w, `
+Flags default to the settings of the project config file found in the
+working directory or its closest parent, named one of:
+  %s
+Top-level settings apply to all commands having the flag, and settings in
+a section named after a command to that command only.
+`, strings.Join(configNames, ", "))
+	fmt.Fprintf(w, `
+exit status:
+  %d  success
+  %d  invalid input, differences found by diff and -check, other failures
+  %d  invalid flags or arguments
+  %d  unreadable input or unwritable output
+  %d  input rejected by -strict
+`, 0, exitInvalid, exitUsage, exitIO, exitStrict)
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the flags of a command.\n", programName)
+}
+
+// processAll formats every input to w, preceded by
+// a header naming the input if there are several.
+func (j *job) processAll(w io.Writer, inputs []string) error {
+	errs := j.forEach(w, inputs, func(w io.Writer, i int) error {
+		if len(inputs) > 1 {
+			fmt.Fprintf(w, "==> %s <==\n", inputs[i])
 		}
+		return j.process(w, inputs[i])
+	})
+	return joinInputErrors(inputs, errs)
+}
 
-		if member == nil && len(members) != 0 {
-			return nil, p.syntaxError(fmt.Errorf("expected object member"))
-		} else if member == nil {
-			break
+// joinInputErrors joins the errors of processing inputs,
+// prefixed with the input if there are several.
+func joinInputErrors(inputs []string, errs []error) error {
+	for i, err := range errs {
+		if err != nil && len(inputs) > 1 {
+			errs[i] = fmt.Errorf("%s: %w", inputs[i], err)
 		}
-
-		members = append(members, member)
-	}
-
-	if r := p.r.read(); r != '}' {
-		return nil, p.expectedError("}", r)
 	}
-
-	return &jsonElement{
-		kind:  objectKind,
-		value: members,
-	}, nil
+	return errors.Join(errs...)
 }
 
-func (p *parser) parseMember() (*pair, error) {
-	r, _ := p.r.peek()
+// rewriteOptions select what rewrite does with files whose formatting differs.
+type rewriteOptions struct {
+	// write replaces the contents of the file.
+	write bool
+	// diff prints the changes as a unified diff.
+	diff bool
+	// list prints the path of the file.
+	list bool
+}
 
-	if r != '"' {
-		return nil, nil
+// rewriteAll rewrites every input, reporting the number of files
+// whose formatting differs.
+func (j *job) rewriteAll(w io.Writer, inputs []string, opts rewriteOptions) (int, error) {
+	changed := make([]bool, len(inputs))
+	errs := j.forEach(w, inputs, func(w io.Writer, i int) error {
+		var err error
+		changed[i], err = j.rewrite(w, inputs[i], opts)
+		return err
+	})
+	var n int
+	for _, ok := range changed {
+		if ok {
+			n++
+		}
 	}
-
-	p.r.read()
-
-	key, err := p.parseRawString()
+	return n, joinInputErrors(inputs, errs)
+}
+
+// job holds what a command resolved from its flags to process every input.
+type job struct {
+	// formatter writes the parsed documents; a nil formatter only parses them.
+	formatter    Formatter
+	settings     settings
+	opts         []jsonparser.Option
+	maxSize      int64
+	client       *http.Client
+	header       http.Header
+	repair       bool
+	ndjson       bool
+	finalNewline bool
+	// lines formats every record of newline-delimited input on its own.
+	lines bool
+	// query selects the value to format in every document, if not nil.
+	query selector
+	// workers is the number of inputs processed in parallel.
+	workers int
+	// stream reformats inputs without building element trees.
+	stream bool
+}
+
+// open opens the document at path like openRaw, decompressing it if needed.
+func (j *job) open(path string) (io.ReadCloser, string, error) {
+	r, name, err := j.openRaw(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	p.eatWhitespace()
-
-	if r = p.r.read(); r != ':' {
-		return nil, p.expectedError(":", r)
-	}
-
-	p.eatWhitespace()
-
-	val, err := p.parseValue()
-	if err != nil {
-		return nil, err
+	if r, err = decompress(r, name); err != nil {
+		return nil, "", err
 	}
-
-	p.eatWhitespace()
-
-	return &pair{
-		key:   key,
-		value: val,
-	}, nil
+	// the limit applies to the decompressed contents too
+	return j.limit(r, name), name, nil
 }
 
-func (p *parser) parseArray() (*jsonElement, error) {
-	p.eatWhitespace()
-
-	var elements []*jsonElement
-
-	for !p.r.isEOF() {
-		if r, _ := p.r.peek(); r == ']' {
-			break
-		}
-
-		if len(elements) != 0 {
-			r := p.r.read()
-			if r != ',' {
-				return nil, p.expectedError(",", r)
-			}
-			p.eatWhitespace()
-		}
-
-		el, err := p.parseValue()
+// openRaw opens the document at path, read from stdin for "-" and
+// downloaded for URLs, returning it with its name for messages.
+func (j *job) openRaw(path string) (io.ReadCloser, string, error) {
+	if isURL(path) {
+		body, err := j.fetch(path)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		elements = append(elements, el)
-
-		p.eatWhitespace()
+		return j.limit(body, path), path, nil
 	}
 
-	p.eatWhitespace()
-
-	if r := p.r.read(); r != ']' {
-		return nil, p.expectedError("]", r)
+	f := os.Stdin
+	if path != "-" {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, "", err
+		}
 	}
 
-	return &jsonElement{
-		kind:  arrayKind,
-		value: elements,
-	}, nil
+	if fi, err := f.Stat(); err != nil {
+		f.Close()
+		return nil, "", err
+	} else if j.maxSize > 0 && fi.Size() > j.maxSize {
+		f.Close()
+		return nil, "", fmt.Errorf("%s is %d bytes, exceeds -max-size of %d bytes: %w", f.Name(), fi.Size(), j.maxSize, jsonparser.ErrInputTooLarge)
+	}
+	if f == os.Stdin {
+		return j.limit(io.NopCloser(f), f.Name()), f.Name(), nil
+	}
+	// the size of special files such as pipes is unknown
+	return j.limit(f, f.Name()), f.Name(), nil
 }
 
-func (p *parser) parseString() (*jsonElement, error) {
-	raw, err := p.parseRawString()
+// process formats the document at path, or read from stdin for "-".
+func (j *job) process(w io.Writer, path string) error {
+	r, name, err := j.open(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &jsonElement{
-		kind:  stringKind,
-		value: raw,
-	}, nil
+	defer r.Close()
+	return j.format(w, r, name)
 }
 
-func (p *parser) parseRawString() ([]byte, error) {
-	if r, _ := p.r.peek(); r == '"' {
-		p.r.read()
-		return []byte{}, nil
+// rewrite formats the file at path and, if the result differs from
+// its contents, handles it as selected by opts writing to w.
+// It reports whether the formatting differs.
+func (j *job) rewrite(w io.Writer, path string, opts rewriteOptions) (bool, error) {
+	if path == "-" || isURL(path) {
+		return false, usageErrorf("-w, -d, -l and -check can only be used with files")
 	}
-
-	start := p.r.offset
-	var escape bool
-	for !p.r.isEOF() {
-		r := p.r.read()
-		if !escape && r == '"' {
-			break
-		}
-
-		if !escape && isSpecialCharacter(r) {
-			return nil, p.syntaxError(fmt.Errorf("unescaped special caharacter %q", r))
-		}
-
-		if escape {
-			switch r {
-			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
-			case 'u':
-				for range 4 {
-					if !isHex(p.r.read()) {
-						return nil, p.expectedError("hexadecimal digit", r)
-					}
-				}
-			default:
-				return nil, p.syntaxError(fmt.Errorf("invalid escape character %q", r))
-			}
-		}
-
-		if !escape && r == '\\' {
-			escape = true
-		} else {
-			escape = false
-		}
-
+	r, _, err := j.openRaw(path)
+	if err != nil {
+		return false, err
 	}
-
-	if start == p.r.offset {
-		return nil, p.syntaxError(fmt.Errorf("expected: \", but 'eof'"))
+	src, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return false, err
 	}
-	return p.r.s[start : p.r.offset-1], nil
-}
-
-func isSpecialCharacter(r rune) bool {
-	return r >= 0 && r <= 31
-}
-
-func isHex(r rune) bool {
-	return isDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F'
-}
-
-func (p *parser) parseNumber(start rune) (*jsonElement, error) {
-	var sb strings.Builder
-	sb.WriteRune(start)
-
-	if err := p.parseInteger(start, &sb); err != nil {
-		return nil, err
+	if compressed(path, src) {
+		return false, errors.New("compressed files cannot be rewritten")
 	}
 
-	if err := p.parseFraction(&sb); err != nil {
-		return nil, err
+	var buf bytes.Buffer
+	if err := j.format(&buf, bytes.NewReader(src), path); err != nil {
+		return false, err
 	}
-
-	if err := p.parseExponent(&sb); err != nil {
-		return nil, err
+	if bytes.Equal(src, buf.Bytes()) {
+		return false, nil
 	}
 
-	return &jsonElement{
-		kind:  numberKind,
-		value: sb.String(),
-	}, nil
-}
-
-func (p *parser) parseInteger(start rune, sb *strings.Builder) error {
-	if start == '-' {
-		r := p.r.read()
-		if r == '0' {
-			sb.WriteRune(r)
-			return nil
+	if opts.list {
+		if _, err := fmt.Fprintln(w, path); err != nil {
+			return true, err
 		}
-		if !isNaturalDigit(r) {
-			return p.expectedError("digit '1-9'", r)
-		}
-		sb.WriteRune(r)
 	}
-
-	if start != '0' {
-		for !p.r.isEOF() {
-			r, _ := p.r.peek()
-			if !isDigit(r) {
-				break
-			}
-			sb.WriteRune(p.r.read())
+	if opts.diff {
+		if _, err := w.Write(unifiedDiff(path+".orig", path, src, buf.Bytes())); err != nil {
+			return true, err
 		}
 	}
-
-	return nil
-}
-
-func (p *parser) parseFraction(sb *strings.Builder) error {
-	r, _ := p.r.peek()
-	if r == '.' {
-		sb.WriteRune('.')
-		p.r.read()
-		var hasDigit bool
-		for !p.r.isEOF() {
-			r, _ := p.r.peek()
-			if !isDigit(r) {
-				break
-			}
-			hasDigit = true
-			sb.WriteRune(p.r.read())
-		}
-
-		if !hasDigit {
-			return p.syntaxError(fmt.Errorf("expected: digit after fraction '.'"))
-		}
+	if !opts.write {
+		return true, nil
 	}
-
-	return nil
+	af, err := createAtomic(path)
+	if err != nil {
+		return true, err
+	}
+	if _, err := af.Write(buf.Bytes()); err != nil {
+		af.Abort()
+		return true, err
+	}
+	return true, af.Commit()
 }
 
-func (p *parser) parseExponent(sb *strings.Builder) error {
-	r, _ := p.r.peek()
-	if r == 'e' || r == 'E' {
-		sb.WriteRune(r)
-		p.r.read()
-		r, _ = p.r.peek()
-		if r == '+' || r == '-' {
-			p.r.read()
-			sb.WriteRune(r)
+// format formats the document read from r, named name in messages.
+func (j *job) format(w io.Writer, r io.Reader, name string) error {
+	if j.lines {
+		return j.formatLines(w, r, name)
+	}
+	if j.stream {
+		var err error
+		if j.formatter.Name() == "pretty" {
+			err = jsonparser.PrettyStream(w, r, j.settings.layout, j.opts...)
+		} else {
+			err = jsonparser.MinifyStream(w, r, j.opts...)
 		}
-
-		var hasDigit bool
-
-		for !p.r.isEOF() {
-			r, _ := p.r.peek()
-			if !isDigit(r) {
-				break
-			}
-			hasDigit = true
-			sb.WriteRune(p.r.read())
+		if err != nil {
+			return err
 		}
-
-		if !hasDigit {
-			return p.syntaxError(fmt.Errorf("expected: digit after exponent"))
+		if j.finalNewline {
+			_, err = fmt.Fprintln(w)
 		}
+		return err
 	}
 
-	return nil
-}
+	json, err := j.decode(r, name)
+	if err != nil {
+		return err
+	}
+	if json, err = j.selectValue(json); err != nil {
+		return err
+	}
 
-func isNaturalDigit(r rune) bool {
-	return r >= '1' && r <= '9'
+	if j.formatter == nil {
+		return nil
+	}
+	s := j.settings
+	s.input = name
+	return j.formatter.Format(w, json, &s)
 }
 
-func isDigit(r rune) bool {
-	return r >= '0' && r <= '9'
+// selectValue returns the value of json selected by the query.
+func (j *job) selectValue(json *jsonparser.Element) (*jsonparser.Element, error) {
+	if j.query == nil {
+		return json, nil
+	}
+	return j.query(json)
 }
 
-func (p *parser) parseBool(start rune) (*jsonElement, error) {
-	switch start {
-	case 't':
-		ok, expected, got := p.match("rue")
-		if ok {
-			return &jsonElement{
-				kind:  booleanKind,
-				value: true,
-			}, nil
+// formatLines formats every record of the newline-delimited input read
+// from r as soon as it is parsed. Invalid records and those without
+// the value selected by the query are logged and skipped.
+func (j *job) formatLines(w io.Writer, r io.Reader, name string) error {
+	var (
+		d       = jsonparser.NewLineDecoder(r, j.opts...)
+		s       = j.settings
+		skipped int
+	)
+	s.input = name
+	for {
+		json, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		var syntaxErr *jsonparser.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			log.Printf("%s: %v", name, err)
+			skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		value, err := j.selectValue(json)
+		if err != nil {
+			log.Printf("%s: line %d: %v", name, json.Span().Start.Line, err)
+			skipped++
+			continue
 		}
 
-		return nil, p.expectedError(string(expected), got)
-	case 'f':
-		ok, expected, got := p.match("alse")
-		if ok {
-			return &jsonElement{
-				kind:  booleanKind,
-				value: false,
-			}, nil
+		if err := j.formatter.Format(w, value, &s); err != nil {
+			return err
 		}
+	}
 
-		return nil, p.expectedError(string(expected), got)
+	switch skipped {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("skipped 1 record")
 	default:
-		panic("unreachable")
+		return fmt.Errorf("skipped %d records", skipped)
 	}
 }
 
-func (p *parser) parseNull() (*jsonElement, error) {
-	ok, expected, got := p.match("ull")
-	if ok {
-		return &jsonElement{
-			kind: nullKind,
-		}, nil
+// parse parses the document at path, or read from stdin for "-".
+func (j *job) parse(path string) (*jsonparser.Element, error) {
+	r, name, err := j.open(path)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, p.expectedError(string(expected), got)
+	defer r.Close()
+	return j.decode(r, name)
 }
 
-func (p *parser) eatWhitespace() {
-	for !p.r.isEOF() {
-		r, _ := p.r.peek()
-
-		if !isWhitespace(r) {
-			break
+// decode parses the document read from r, named name in messages.
+func (j *job) decode(r io.Reader, name string) (*jsonparser.Element, error) {
+	switch {
+	case j.repair:
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
 		}
-
-		p.r.read()
-	}
-}
-
-func isWhitespace(r rune) bool {
-	switch r {
-	case ' ', '\t', '\n', '\r':
-		return true
+		json, fixes, err := jsonparser.Repair(b, j.opts...)
+		for _, fix := range fixes {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", name, fix)
+		}
+		return json, err
+	case j.ndjson:
+		return jsonparser.ParseLines(r, j.opts...)
 	default:
-		return false
+		return jsonparser.NewReaderParser(r, j.opts...).Parse()
 	}
 }
 
-func (p *parser) match(s string) (bool, rune, rune) {
-	for _, ss := range s {
-		r := p.r.read()
-		if r != ss {
-			return false, ss, r
+// useColor reports whether to colorize output according to the -color flag.
+// In auto mode, colors are used if stdout is a terminal and NO_COLOR is not set.
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
 		}
+		fi, err := os.Stdout.Stat()
+		if err != nil {
+			return false, nil
+		}
+		return fi.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, usageErrorf("unsupported color mode: %q", mode)
 	}
-
-	return true, 0, 0
-}
-
-func (p *parser) expectedError(expected string, got rune) error {
-	return p.syntaxError(
-		fmt.Errorf(
-			"expected: %q, but got: %q",
-			expected, string(got)),
-	)
-}
-
-func (p *parser) syntaxError(err error) error {
-	return fmt.Errorf(
-		"syntax error in JSON at line %d, column %d: %w", p.r.line, p.r.col, err,
-	)
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// writeInput writes content to a file in a temporary directory
+// and returns its path.
+func writeInput(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// captureStdout runs f with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, f func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- b
+	}()
+	err = f()
+	w.Close()
+	return string(<-out), err
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	input := writeInput(t, "in.json", `{"a": [1, 2]}`)
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "negative indent", args: []string{"fmt", "-indent", "-1", input}},
+		{name: "negative xml indent", args: []string{"xml", "-indent", "-1", input}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			err := run(append([]string{tt.args[0], "-o", out}, tt.args[1:]...))
+			var exit *exitError
+			if !errors.As(err, &exit) || exit.code != exitUsage {
+				t.Fatalf("got error %v, want a usage error", err)
+			}
+		})
+	}
+}
+
+func TestRunInvalidInput(t *testing.T) {
+	input := writeInput(t, "in.json", `{"a": [1, 2}`)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "pretty", args: []string{"pretty", input}},
+		{name: "minify", args: []string{"minify", input}},
+		{name: "minify streamed", args: []string{"minify", "-stream", input}, want: `{"a":[1,2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := captureStdout(t, func() error { return run(tt.args) })
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got != tt.want {
+				t.Errorf("got output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunMaxSize(t *testing.T) {
+	doc := "[" + strings.Repeat("0,", 500) + "0]"
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(doc))
+	zw.Close()
+	plain := writeInput(t, "in.json", doc)
+	compressed := writeInput(t, "in.json.gz", gz.String())
+
+	tests := []struct {
+		name     string
+		args     []string
+		tooLarge bool
+	}{
+		{name: "file at the limit", args: []string{"minify", "-max-size", "1003", plain}},
+		{name: "file over the limit", args: []string{"minify", "-max-size", "1002", plain}, tooLarge: true},
+		{name: "decompressed input over the limit", args: []string{"minify", "-max-size", "500", compressed}, tooLarge: true},
+		{name: "repaired input over the limit", args: []string{"minify", "-repair", "-max-size", "500", compressed}, tooLarge: true},
+		{name: "no limit", args: []string{"minify", "-max-size", "0", compressed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			err := run(append([]string{tt.args[0], "-o", out}, tt.args[1:]...))
+			if tt.tooLarge != errors.Is(err, jsonparser.ErrInputTooLarge) {
+				t.Errorf("got error %v", err)
+			}
+			if !tt.tooLarge && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRunZstdInput(t *testing.T) {
+	input := writeInput(t, "in.json.zst", "\x28\xb5\x2f\xfd\x00")
+	out := filepath.Join(t.TempDir(), "out")
+	err := run([]string{"minify", "-o", out, input})
+	if err == nil || err.Error() != "zstd compressed input is not supported" {
+		t.Errorf("got error %v, want zstd input to be rejected", err)
+	}
+}
+
+func TestRunProjection(t *testing.T) {
+	input := writeInput(t, "in.json", `[{"id": 1, "password": "x"}, {"name": "b"}]`)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "pick", args: []string{"minify", "-pick", "id", input}, want: `[{"id":1}]`},
+		{name: "omit", args: []string{"minify", "-omit", "**.password", input}, want: `[{"id":1},{"name":"b"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			if err := run(append([]string{tt.args[0], "-o", out}, tt.args[1:]...)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := strings.TrimSpace(string(got)); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile is a temporary file replacing the file at path once committed,
+// so that the destination is never left partially written.
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+// createAtomic creates a temporary file next to path.
+func createAtomic(path string) (*atomicFile, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+// Commit renames the temporary file to the destination,
+// keeping the permissions of the file it replaces.
+func (f *atomicFile) Commit() error {
+	mode := fs.FileMode(0o644)
+	if fi, err := os.Stat(f.path); err == nil {
+		mode = fi.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		f.Abort()
+		return err
+	}
+
+	err := f.Chmod(mode)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Abort removes the temporary file, leaving the destination untouched.
+func (f *atomicFile) Abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// openOutput returns stdout for an empty path and a buffered atomic file
+// replacing the file at path otherwise. The returned function finishes
+// writing, committing the file if err is nil and discarding it otherwise.
+func openOutput(path string) (io.Writer, func(err error) error, error) {
+	if path == "" {
+		return os.Stdout, func(err error) error { return err }, nil
+	}
+	f, err := createAtomic(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := bufio.NewWriter(f)
+	return w, func(err error) error {
+		if err == nil {
+			err = w.Flush()
+		}
+		if err == nil {
+			return f.Commit()
+		}
+		f.Abort()
+		return err
+	}, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// forEach calls fn for the index of every input, running up to j.workers
+// calls at a time. The output of every call is buffered and written to w
+// in the order of the inputs, so that it does not depend on scheduling.
+// It returns the errors of the calls by index.
+func (j *job) forEach(w io.Writer, inputs []string, fn func(w io.Writer, i int) error) []error {
+	errs := make([]error, len(inputs))
+	if j.workers <= 1 || len(inputs) <= 1 {
+		for i := range inputs {
+			errs[i] = fn(w, i)
+		}
+		return errs
+	}
+
+	type result struct {
+		out bytes.Buffer
+		err error
+	}
+	var (
+		results = make([]chan *result, len(inputs))
+		// slots limits the calls running or waiting to be written
+		slots = make(chan struct{}, j.workers)
+	)
+	for i := range results {
+		results[i] = make(chan *result, 1)
+	}
+	go func() {
+		for i := range inputs {
+			slots <- struct{}{}
+			go func() {
+				r := new(result)
+				r.err = fn(&r.out, i)
+				results[i] <- r
+			}()
+		}
+	}()
+
+	var failed bool
+	for i := range inputs {
+		r := <-results[i]
+		errs[i] = r.err
+		// stop writing after an error, which is most likely to recur
+		if !failed {
+			if _, err := r.out.WriteTo(w); err != nil {
+				failed = true
+				errs[i] = errors.Join(errs[i], err)
+			}
+		}
+		<-slots
+	}
+	return errs
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// selector returns the value of a document selected by a query.
+type selector func(json *jsonparser.Element) (*jsonparser.Element, error)
+
+// parseQuery returns the selector of the query q, which is either a
+// JSONPath expression starting with $, selecting an array of the values
+// it matches, or a JSON Pointer or path expression as of queryPointer.
+func parseQuery(q string) (selector, error) {
+	if !strings.HasPrefix(q, "$") {
+		ptr, err := queryPointer(q)
+		if err != nil {
+			return nil, err
+		}
+		return func(json *jsonparser.Element) (*jsonparser.Element, error) {
+			return jsonparser.ResolvePointer(json, ptr)
+		}, nil
+	}
+
+	// report syntax errors before reading any input
+	null, _ := jsonparser.FromValue(nil)
+	if _, err := jsonparser.Query(null, q); err != nil {
+		return nil, &exitError{code: exitUsage, err: err}
+	}
+	return func(json *jsonparser.Element) (*jsonparser.Element, error) {
+		nodes, err := jsonparser.Query(json, q)
+		if err != nil {
+			return nil, err
+		}
+		values, _ := jsonparser.FromValue([]any{})
+		for _, n := range nodes {
+			values.Append(n)
+		}
+		return values, nil
+	}, nil
+}
+
+// chain returns the selector applying next to the value selected
+// by sel, or to the document if sel is nil.
+func chain(sel, next selector) selector {
+	if sel == nil {
+		return next
+	}
+	return func(json *jsonparser.Element) (*jsonparser.Element, error) {
+		json, err := sel(json)
+		if err != nil {
+			return nil, err
+		}
+		return next(json)
+	}
+}
+
+// filterSelector returns the selector of the elements of an array
+// for which the jq-like expression expr is true.
+func filterSelector(expr string) (selector, error) {
+	f, err := jsonparser.CompileFilter(expr)
+	if err != nil {
+		return nil, &exitError{code: exitUsage, err: err}
+	}
+	return func(json *jsonparser.Element) (*jsonparser.Element, error) {
+		var evalErr error
+		filtered, err := jsonparser.FilterArray(json, func(el *jsonparser.Element) bool {
+			ok, err := f.Match(el)
+			if evalErr == nil {
+				evalErr = err
+			}
+			return ok
+		})
+		if err != nil {
+			return nil, err
+		}
+		return filtered, evalErr
+	}, nil
+}
+
+// sortSelector returns the selector of an array sorted by the outputs
+// of the jq-like expression by, in the order named by order.
+func sortSelector(by, order string) (selector, error) {
+	if _, err := jsonparser.CompileFilter(by); err != nil {
+		return nil, &exitError{code: exitUsage, err: err}
+	}
+	var o jsonparser.SortOrder
+	switch order {
+	case "asc":
+	case "desc":
+		o = jsonparser.Descending
+	default:
+		return nil, usageErrorf("unsupported sort order: %q", order)
+	}
+	return func(json *jsonparser.Element) (*jsonparser.Element, error) {
+		return jsonparser.SortArray(json, by, o)
+	}, nil
+}
+
+// projectSelector returns the selector of the document keeping only the
+// members at the dotted paths of pick, if any, without those of omit.
+func projectSelector(pick, omit []string) selector {
+	return func(json *jsonparser.Element) (*jsonparser.Element, error) {
+		if len(pick) > 0 {
+			json = jsonparser.Pick(json, pick...)
+		}
+		if len(omit) > 0 {
+			json = jsonparser.Omit(json, omit...)
+		}
+		return json, nil
+	}
+}
+
+// queryPointer returns the JSON Pointer selected by the -q flag, which is
+// either a JSON Pointer itself or a path expression like .store.book[0].title
+// of member names after dots, and of array indices or quoted member
+// names in brackets.
+func queryPointer(q string) (string, error) {
+	if q == "" || q[0] == '/' {
+		return q, nil
+	}
+
+	var (
+		sb   strings.Builder
+		rest = q
+	)
+	token := func(t string) {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+	}
+	for i := 0; rest != ""; i++ {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return "", usageErrorf("invalid path expression %q: missing ']'", q)
+			}
+			inner := rest[1:end]
+			if strings.HasPrefix(inner, `"`) {
+				// the closing bracket may be part of the quoted name
+				s, err := strconv.QuotedPrefix(rest[1:])
+				if err != nil || !strings.HasPrefix(rest[1+len(s):], "]") {
+					return "", usageErrorf("invalid path expression %q: bad quoted name", q)
+				}
+				key, _ := strconv.Unquote(s)
+				token(key)
+				rest = rest[len(s)+2:]
+				continue
+			}
+			if _, err := strconv.ParseUint(inner, 10, 0); err != nil {
+				return "", usageErrorf("invalid path expression %q: bad index %q", q, inner)
+			}
+			token(inner)
+			rest = rest[end+1:]
+		case rest[0] == '.' || i == 0:
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				if rest == "" && i == 0 {
+					// "." selects the whole document
+					return "", nil
+				}
+				return "", usageErrorf("invalid path expression %q: empty member name", q)
+			}
+			token(rest[:end])
+			rest = rest[end:]
+		default:
+			return "", usageErrorf("invalid path expression %q: unexpected %q", q, rest[0])
+		}
+	}
+	return sb.String(), nil
+}
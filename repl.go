@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+func replCommand() *command {
+	c := &command{
+		name: "repl",
+		args: "file",
+		help: "Explore a document interactively, navigating it like a file system.",
+	}
+	c.setup = func(fs *flag.FlagSet) func([]string) error {
+		in := addInputFlags(fs)
+		style := addStyleFlags(fs, true)
+
+		return func(args []string) error {
+			if len(args) != 1 {
+				fs.Usage()
+				return &exitError{code: exitUsage}
+			}
+			j, err := in.job(nil)
+			if err != nil {
+				return err
+			}
+			// exported files are written without colors
+			if err := style.configure(j, false); err != nil {
+				return err
+			}
+			exportOpts := j.settings.formatOpts
+			if err := style.configure(j, true); err != nil {
+				return err
+			}
+
+			root, err := j.parse(args[0])
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			r := &repl{
+				root: root,
+				out:  os.Stdout,
+				show: func(w io.Writer, el *jsonparser.Element) error {
+					return jsonparser.WritePrettyIndent(w, j.settings.transformed(el), j.settings.layout, j.settings.formatOpts...)
+				},
+				export: func(w io.Writer, el *jsonparser.Element) error {
+					return jsonparser.WritePrettyIndent(w, j.settings.transformed(el), j.settings.layout, exportOpts...)
+				},
+			}
+			fi, err := os.Stdin.Stat()
+			r.prompt = err == nil && fi.Mode()&os.ModeCharDevice != 0
+			return r.run(os.Stdin)
+		}
+	}
+	return c
+}
+
+// repl runs the commands of the repl command on a document.
+// Paths name values like files, with the tokens of a JSON Pointer
+// separated by slashes; they are relative to the current value
+// unless they start with a slash, and ".." selects the parent.
+type repl struct {
+	root *jsonparser.Element
+	// cwd holds the escaped pointer tokens of the current value.
+	cwd []string
+	out io.Writer
+	// prompt is printed before reading every line if set.
+	prompt bool
+	// show writes values for the terminal, export for files.
+	show, export func(w io.Writer, el *jsonparser.Element) error
+}
+
+// errQuit stops the repl.
+var errQuit = errors.New("quit")
+
+const replHelp = `commands:
+  cd [path]           select the value at path, or the document
+  ls [path]           list the members or elements of a value
+  cat [path]          print a value
+  pwd                 print the path of the current value
+  find text           list the values below the current one whose key or
+                      scalar value contains text, ignoring case
+  export file [path]  write a value to file
+  help                print this text
+  quit                leave
+`
+
+// run executes the commands read from r line by line until
+// the input ends or a quit command.
+func (r *repl) run(in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	for {
+		if r.prompt {
+			fmt.Fprintf(r.out, "%s> ", r.pointer(r.cwd))
+		}
+		if !sc.Scan() {
+			break
+		}
+		err := r.exec(strings.TrimSpace(sc.Text()))
+		if err == errQuit {
+			return nil
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+	if r.prompt {
+		fmt.Fprintln(r.out)
+	}
+	return sc.Err()
+}
+
+// exec executes a command line.
+func (r *repl) exec(line string) error {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "":
+		return nil
+	case "cd":
+		tokens, el, err := r.resolve(arg)
+		if err != nil {
+			return err
+		}
+		if !isContainer(el) {
+			return fmt.Errorf("%s is a %s", r.pointer(tokens), el.Kind())
+		}
+		r.cwd = tokens
+		return nil
+	case "ls":
+		_, el, err := r.resolve(arg)
+		if err != nil {
+			return err
+		}
+		return r.list(el)
+	case "cat":
+		_, el, err := r.resolve(arg)
+		if err != nil {
+			return err
+		}
+		return r.show(r.out, el)
+	case "pwd":
+		_, err := fmt.Fprintln(r.out, r.pointer(r.cwd))
+		return err
+	case "find":
+		if arg == "" {
+			return errors.New("usage: find text")
+		}
+		_, el, err := r.resolve("")
+		if err != nil {
+			return err
+		}
+		return r.find(el, r.cwd, strings.ToLower(arg))
+	case "export":
+		file, path, _ := strings.Cut(arg, " ")
+		if file == "" {
+			return errors.New("usage: export file [path]")
+		}
+		_, el, err := r.resolve(strings.TrimSpace(path))
+		if err != nil {
+			return err
+		}
+		f, err := createAtomic(file)
+		if err != nil {
+			return err
+		}
+		if err := r.export(f, el); err != nil {
+			f.Abort()
+			return err
+		}
+		return f.Commit()
+	case "help":
+		_, err := io.WriteString(r.out, replHelp)
+		return err
+	case "quit", "exit":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command %q, see help", name)
+	}
+}
+
+// resolve returns the tokens of path and the value they select.
+func (r *repl) resolve(path string) ([]string, *jsonparser.Element, error) {
+	var tokens []string
+	if !strings.HasPrefix(path, "/") {
+		tokens = append(tokens, r.cwd...)
+	}
+	for _, t := range strings.Split(path, "/") {
+		switch t {
+		case "", ".":
+		case "..":
+			if len(tokens) > 0 {
+				tokens = tokens[:len(tokens)-1]
+			}
+		default:
+			tokens = append(tokens, t)
+		}
+	}
+	var ptr string
+	if len(tokens) > 0 {
+		ptr = r.pointer(tokens)
+	}
+	el, err := jsonparser.ResolvePointer(r.root, ptr)
+	return tokens, el, err
+}
+
+// pointer returns the path of tokens, which is "/" for the document
+// and the JSON Pointer of the value otherwise.
+func (r *repl) pointer(tokens []string) string {
+	return "/" + strings.Join(tokens, "/")
+}
+
+// list writes the members or elements of el with their kinds
+// and a preview of their values.
+func (r *repl) list(el *jsonparser.Element) error {
+	tw := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	if members, ok := el.Object(); ok {
+		for _, m := range members {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", m.Key(), m.Value().Kind(), preview(m.Value()))
+		}
+	} else if elements, ok := el.Array(); ok {
+		for i, e := range elements {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", i, e.Kind(), preview(e))
+		}
+	} else {
+		fmt.Fprintf(tw, "%s\t%s\n", el.Kind(), preview(el))
+	}
+	return tw.Flush()
+}
+
+// find writes the pointers and previews of the values below el, whose
+// pointer tokens are tokens, with a key or scalar value containing text.
+func (r *repl) find(el *jsonparser.Element, tokens []string, text string) error {
+	match := func(tokens []string, key string, value *jsonparser.Element) error {
+		if strings.Contains(strings.ToLower(key), text) || !isContainer(value) &&
+			strings.Contains(strings.ToLower(jsonparser.Minify(value)), text) {
+			_, err := fmt.Fprintf(r.out, "%s\t%s\n", r.pointer(tokens), preview(value))
+			if err != nil {
+				return err
+			}
+		}
+		return r.find(value, tokens, text)
+	}
+	if members, ok := el.Object(); ok {
+		for _, m := range members {
+			escaped := strings.NewReplacer("~", "~0", "/", "~1").Replace(m.Key())
+			if err := match(append(tokens[:len(tokens):len(tokens)], escaped), m.Key(), m.Value()); err != nil {
+				return err
+			}
+		}
+	} else if elements, ok := el.Array(); ok {
+		for i, e := range elements {
+			if err := match(append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)), "", e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isContainer(el *jsonparser.Element) bool {
+	kind := el.Kind()
+	return kind == jsonparser.ObjectKind || kind == jsonparser.ArrayKind
+}
+
+// preview returns a short description of the value of el:
+// the number of members or elements, or the truncated scalar.
+func preview(el *jsonparser.Element) string {
+	if members, ok := el.Object(); ok {
+		return fmt.Sprintf("{%d}", len(members))
+	}
+	if elements, ok := el.Array(); ok {
+		return fmt.Sprintf("[%d]", len(elements))
+	}
+	const max = 60
+	s := jsonparser.Minify(el)
+	if len(s) > max {
+		i := max - 3
+		for !utf8.RuneStart(s[i]) {
+			i--
+		}
+		s = s[:i] + "..."
+	}
+	return s
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoTerminal = errors.New("the terminal UI is only supported on Unix systems")
+
+func openTerminal() (*os.File, func() error, error) {
+	return nil, nil, errNoTerminal
+}
+
+func terminalSize(*os.File) (int, int, error) {
+	return 0, 0, errNoTerminal
+}
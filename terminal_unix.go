@@ -0,0 +1,59 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// openTerminal opens the controlling terminal in raw mode
+// and returns a function restoring its mode and closing it.
+func openTerminal() (*os.File, func() error, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := stty(tty, "-g")
+	if err == nil {
+		_, err = stty(tty, "raw", "-echo")
+	}
+	if err != nil {
+		tty.Close()
+		return nil, nil, err
+	}
+	restore := func() error {
+		_, err := stty(tty, strings.TrimSpace(state))
+		if cerr := tty.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	return tty, restore, nil
+}
+
+// terminalSize returns the number of rows and columns of tty.
+func terminalSize(tty *os.File) (int, int, error) {
+	out, err := stty(tty, "size")
+	if err != nil {
+		return 0, 0, err
+	}
+	var rows, cols int
+	if _, err := fmt.Sscan(out, &rows, &cols); err != nil {
+		return 0, 0, fmt.Errorf("terminal size: %w", err)
+	}
+	return rows, cols, nil
+}
+
+// stty runs stty with args on tty, returning its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
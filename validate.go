@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nikpivkin/go-json-parser/jsonparser"
+)
+
+// validationError is an error reported by the validate mode with -error-format json.
+type validationError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Offset  int    `json:"offset"`
+	Message string `json:"message"`
+	// Invalid is false if the file could not be read,
+	// the position is then zero.
+	Invalid bool `json:"invalid"`
+}
+
+// validate parses every input, reporting the errors found
+// as text in the returned error or as JSON lines written to w.
+// The exit code is the highest one of the failures.
+func (j *job) validate(w io.Writer, inputs []string, jsonErrors bool) error {
+	var (
+		code    int
+		reports []validationError
+	)
+	errs := j.forEach(io.Discard, inputs, func(w io.Writer, i int) error {
+		return j.process(w, inputs[i])
+	})
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		path := inputs[i]
+		code = max(code, exitCode(err))
+
+		var (
+			syntaxErr  *jsonparser.SyntaxError
+			syntaxErrs jsonparser.SyntaxErrors
+		)
+		switch {
+		case errors.As(err, &syntaxErrs):
+		case errors.As(err, &syntaxErr):
+			syntaxErrs = jsonparser.SyntaxErrors{*syntaxErr}
+		default:
+			reports = append(reports, validationError{File: path, Message: err.Error()})
+			continue
+		}
+		for _, e := range syntaxErrs {
+			reports = append(reports, validationError{
+				File:    path,
+				Line:    e.Line,
+				Column:  e.Col,
+				Offset:  e.Offset,
+				Message: e.Msg,
+				Invalid: true,
+			})
+		}
+	}
+	if code == 0 {
+		return nil
+	}
+
+	if !jsonErrors {
+		errs := make([]error, len(reports))
+		for i, r := range reports {
+			if r.Invalid {
+				errs[i] = fmt.Errorf("%s:%d:%d: %s", r.File, r.Line, r.Column, r.Message)
+			} else {
+				errs[i] = fmt.Errorf("%s: %s", r.File, r.Message)
+			}
+		}
+		return &exitError{code: code, err: errors.Join(errs...)}
+	}
+
+	for _, r := range reports {
+		b, err := jsonparser.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", b); err != nil {
+			return err
+		}
+	}
+	return &exitError{code: code}
+}